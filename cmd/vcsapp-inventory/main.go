@@ -0,0 +1,58 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cidverse/go-vcsapp/pkg/vcsapp"
+	"github.com/rs/zerolog/log"
+)
+
+func main() {
+	format := flag.String("format", "json", "output format (json, csv, yaml)")
+	output := flag.String("output", "", "output file, defaults to stdout")
+	botUsers := flag.String("bot-users", "", "comma-separated list of usernames whose merge requests are counted as bot merge requests")
+	flag.Parse()
+
+	if err := run(*format, *output, *botUsers); err != nil {
+		log.Fatal().Err(err).Msg("failed to export inventory")
+	}
+}
+
+func run(formatName string, output string, botUsers string) (err error) {
+	// validate the format before crawling all repositories
+	format, err := vcsapp.ParseInventoryFormat(formatName)
+	if err != nil {
+		return err
+	}
+
+	// platform
+	platform, err := vcsapp.GetPlatformFromEnvironment()
+	if err != nil {
+		return fmt.Errorf("failed to configure platform: %w", err)
+	}
+
+	// output
+	out := os.Stdout
+	if output != "" {
+		out, err = os.Create(output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file %s: %w", output, err)
+		}
+		defer func() {
+			if closeErr := out.Close(); closeErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to close output file %s: %w", output, closeErr))
+			}
+		}()
+	}
+
+	opts := vcsapp.InventoryOpts{}
+	if botUsers != "" {
+		opts.BotUsernames = strings.Split(botUsers, ",")
+	}
+
+	return vcsapp.ExportInventory(platform, out, format, opts)
+}
@@ -15,28 +15,64 @@ type Platform interface {
 	Slug() string
 	// Repositories returns a list of all repositories we have access to
 	Repositories(opts RepositoryListOpts) ([]Repository, error)
-	// FindRepository returns one repository by its name
+	// FindRepository returns one repository by its path (e.g. organization/repo), numeric id or clone url
 	FindRepository(name string) (Repository, error)
+	// Branches returns a list of all branches of a repository
+	Branches(repository Repository) ([]Branch, error)
 	// MergeRequests returns a list of all pull requests created by us
 	MergeRequests(repository Repository, options MergeRequestSearchOptions) ([]MergeRequest, error)
+	// FindMergeRequestByKey returns the merge request with the given vcs-merge-request-key, preferring open merge requests over closed / merged ones, returns ErrNotFound if none exists.
+	// openOnly limits the search to open merge requests, which avoids listing the full merge request history on GitHub.
+	FindMergeRequestByKey(repository Repository, key string, openOnly bool) (MergeRequest, error)
+	// GetMergeRequest returns a single merge request by its number, including commits, approvals, pipeline state and mergeability
+	GetMergeRequest(repository Repository, number int) (MergeRequest, error)
 	// MergeRequestDiff returns all changes of a merge request
 	MergeRequestDiff(repo Repository, mergeRequest MergeRequest) (MergeRequestDiff, error)
+	// MergeRequestComments returns all comments of a merge request
+	MergeRequestComments(repo Repository, mergeRequest MergeRequest) ([]MergeRequestComment, error)
+	// CreateMergeRequestComment adds a comment to a merge request
+	CreateMergeRequestComment(repo Repository, mergeRequest MergeRequest, body string) (MergeRequestComment, error)
+	// UpdateMergeRequestComment updates the body of an existing merge request comment
+	UpdateMergeRequestComment(repo Repository, mergeRequest MergeRequest, commentId int64, body string) (MergeRequestComment, error)
+	// DeleteMergeRequestComment deletes a merge request comment
+	DeleteMergeRequestComment(repo Repository, mergeRequest MergeRequest, commentId int64) error
 	// SubmitReview submits a review result / approval for a merge request
 	SubmitReview(repo Repository, mergeRequest MergeRequest, approved bool, message *string) error
+	// ApprovalState returns the approval status of a merge request, including the approval rules of the target branch
+	ApprovalState(repo Repository, mergeRequest MergeRequest) (ApprovalState, error)
+	// CreateReview submits a review with inline comments anchored to lines of the merge request diff
+	CreateReview(repo Repository, mergeRequest MergeRequest, review Review) error
 	// Merge merges a merge request
 	Merge(repo Repository, mergeRequest MergeRequest, mergeStrategy MergeStrategyOptions) error
+	// EnableAutoMerge enables auto-merge (GitHub) / merge when pipeline succeeds (GitLab), the merge request is merged using the given strategy once all checks passed
+	EnableAutoMerge(repo Repository, mergeRequest MergeRequest, mergeStrategy MergeStrategyOptions) error
+	// CloseMergeRequest closes a merge request without merging, optionally adding a comment explaining why
+	CloseMergeRequest(repo Repository, mergeRequest MergeRequest, message *string) error
+	// ReopenMergeRequest reopens a closed merge request
+	ReopenMergeRequest(repo Repository, mergeRequest MergeRequest) error
+	// DeleteBranch deletes a branch
+	DeleteBranch(repo Repository, branch string) error
 	// Languages returns a map of used languages and their line count
 	Languages(repository Repository) (map[string]int, error)
 	// AuthMethod returns the authentication method used by the platform, required to push changes
 	AuthMethod(repository Repository) githttp.AuthMethod
-	// CommitAndPush creates a commit in the repository and pushes it to the remote
+	// CommitAndPush creates a commit in the repository and pushes it to the remote, use PushCommits to add trailers or a sign-off
 	CommitAndPush(repository Repository, base string, branch string, message string, dir string) error
+	// PushCommits creates a sequence of commits from the changes in dir and pushes them to the remote branch, see AssignCommitPaths
+	PushCommits(repository Repository, base string, branch string, commits []Commit, dir string) error
 	// CreateMergeRequest creates a merge request
-	CreateMergeRequest(repository Repository, sourceBranch string, title string, description string) error
-	// CreateOrUpdateMergeRequest creates a merge request
-	CreateOrUpdateMergeRequest(repository Repository, sourceBranch string, title string, description string, key string) error
+	CreateMergeRequest(repository Repository, sourceBranch string, title string, description string, options MergeRequestOptions) (MergeRequest, error)
+	// CreateOrUpdateMergeRequest creates a merge request or updates the existing one, returns the merge request and whether it was created, updated or left unchanged
+	CreateOrUpdateMergeRequest(repository Repository, sourceBranch string, title string, description string, key string, options MergeRequestOptions) (MergeRequest, MergeRequestOutcome, error)
+	// ListFiles returns the files and directories at a path in the repository, optionally including all subdirectories.
+	// If the listing exceeds the limits of the platform api, the partial result is returned with an error wrapping ErrTruncated.
+	ListFiles(repository Repository, ref string, path string, recursive bool) ([]TreeEntry, error)
 	// FileContent returns the content of a file
 	FileContent(repository Repository, branch string, path string) (string, error)
+	// File returns the binary content and metadata of a file, returns ErrNotFound if the file does not exist
+	File(repository Repository, ref string, path string) (File, error)
+	// FileExists checks if a file exists
+	FileExists(repository Repository, ref string, path string) (bool, error)
 	// Tags returns a list of all tags
 	Tags(repository Repository, limit int) ([]Tag, error)
 	// Releases returns a list of all releases
@@ -52,32 +88,41 @@ type Platform interface {
 }
 
 type Repository struct {
-	PlatformId        string            // the platform id
-	PlatformType      string            // the platform type
-	Id                int64             // the id of the repository
-	Namespace         string            // the namespace of the repository (e.g. organization or user)
-	Name              string            // the name of the repository
-	Path              string            // the path of the repository (e.g. organization/repo)
-	Description       string            // the description of the repository
-	Type              string            // repository type - valid values: git
-	URL               string            // the url of the repository
-	CloneURL          string            // the clone url of the repository
-	CloneSSH          string            // the clone ssh url of the repository
-	DefaultBranch     string            // the default branch of the repository
-	IsFork            bool              // is this repository a fork
-	IsEmpty           bool              // is this repository empty (no commits)
-	IsPersonalProject bool              // true if repository owner is a personal user account
-	Branches          []string          // list of all branches
-	Topics            []string          // list of all topics
-	Plan              string            // the plan of the repository (e.g. free, pro, etc. - directly using the platform-specific plan name)
-	LicenseName       string            // the name of the license
-	LicenseURL        string            // the url of the license
-	CommitHash        string            // the commit hash of the latest commit on the default branch
-	CommitDate        *time.Time        // the commit date of the latest commit on the default branch
-	CreatedAt         *time.Time        // the creation date of the repository
-	RoundTripper      http.RoundTripper `json:"-" yaml:"-"` // this is a platform specific round tripper for the repository (GitHub apps require an org-scoped round tripper)
-	InternalClient    interface{}       `json:"-" yaml:"-"` // this is a platform specific client for the repository (GitHub apps require an org-scoped client)
-	InternalRepo      interface{}       `json:"-" yaml:"-"` // this is the original repository object from the platform
+	PlatformId            string               // the platform id
+	PlatformType          string               // the platform type
+	Id                    int64                // the id of the repository
+	Namespace             string               // the namespace of the repository (e.g. organization or user)
+	Name                  string               // the name of the repository
+	Path                  string               // the path of the repository (e.g. organization/repo)
+	Description           string               // the description of the repository
+	Type                  string               // repository type - valid values: git
+	URL                   string               // the url of the repository
+	CloneURL              string               // the clone url of the repository
+	CloneSSH              string               // the clone ssh url of the repository
+	DefaultBranch         string               // the default branch of the repository
+	IsFork                bool                 // is this repository a fork
+	IsEmpty               bool                 // is this repository empty (no commits)
+	IsPersonalProject     bool                 // true if repository owner is a personal user account
+	Branches              []string             // list of all branches
+	Topics                []string             // list of all topics
+	Plan                  string               // the plan of the repository (e.g. free, pro, etc. - directly using the platform-specific plan name)
+	LicenseName           string               // the name of the license
+	LicenseURL            string               // the url of the license
+	CommitHash            string               // the commit hash of the latest commit on the default branch
+	CommitDate            *time.Time           // the commit date of the latest commit on the default branch
+	CreatedAt             *time.Time           // the creation date of the repository
+	Visibility            RepositoryVisibility // the visibility of the repository
+	IsArchived            bool                 // is this repository archived (read-only)
+	Language              string               // the primary language of the repository
+	StarCount             int                  // the number of stars
+	ForkCount             int                  // the number of forks
+	Size                  int64                // the size of the repository in kilobytes
+	OpenMergeRequestCount int                  // the number of open merge requests (requires IncludeMergeRequestCount)
+	LastActivityAt        *time.Time           // the date of the last activity (e.g. push) in the repository
+	WebURL                string               // the web url of the repository, including the scheme
+	RoundTripper          http.RoundTripper    `json:"-" yaml:"-"` // this is a platform specific round tripper for the repository (GitHub apps require an org-scoped round tripper)
+	InternalClient        interface{}          `json:"-" yaml:"-"` // this is a platform specific client for the repository (GitHub apps require an org-scoped client)
+	InternalRepo          interface{}          `json:"-" yaml:"-"` // this is the original repository object from the platform
 }
 
 type MergeRequest struct {
@@ -91,32 +136,121 @@ type MergeRequest struct {
 	Description string
 	// Labels is a list of labels assigned to the merge request
 	Labels []string
+	// Assignees is a list of users assigned to the merge request
+	Assignees []User
+	// Reviewers is a list of users requested to review the merge request, team review requests on GitHub are listed as organization/team
+	Reviewers []User
+	// Milestone is the title of the milestone
+	Milestone string
 	// SourceBranch is the source branch of the merge request
 	SourceBranch string
 	// TargetBranch is the target branch of the merge request
 	TargetBranch string
 	// State is the state of the merge request
 	State MergeRequestState
-	// PipelineState is the state of the pipeline
+	// PipelineState is the state of the pipeline, only populated if requested (see MergeRequestSearchOptions.IncludePipelineState)
 	PipelineState PipelineState
+	// Checks is the per-check breakdown of the pipeline state (GitHub check runs and commit statuses, GitLab jobs of the head pipeline)
+	Checks []MergeRequestCheck
 	// IsMerged is true if the merge request is merged
 	IsMerged bool
 	// IsLocked is true if the merge request is locked
 	IsLocked bool
 	// IsDraft is true if the merge request is a work in progress / not ready for review
 	IsDraft bool
-	// HasConflicts is true if the merge request has conflicts
+	// IsAutoMergeEnabled is true if the merge request will be merged automatically once all checks passed
+	IsAutoMergeEnabled bool
+	// Squash is true if the commits are squashed on merge, nil if the platform has no per merge request setting (GitHub)
+	Squash *bool
+	// RemoveSourceBranch is true if the source branch is removed on merge, nil if the platform has no per merge request setting (GitHub)
+	RemoveSourceBranch *bool
+	// AllowMaintainerEdit is true if maintainers of the target repository can push to the source branch
+	AllowMaintainerEdit *bool
+	// HasConflicts is true if the merge request has conflicts, only reliable from GetMergeRequest on GitHub
 	HasConflicts bool
 	// CanMerge is true if the merge request can be merged (no conflicts, no unresolved discussions, no work in progress, pipeline passed)
+	// Only reliable from GetMergeRequest on GitHub, the list endpoint does not compute the mergeability.
 	CanMerge bool
 	// Author is the author of the merge request
 	Author User
+	// WebURL is the url of the merge request in the web interface
+	WebURL string
+	// HeadSHA is the commit sha of the head of the source branch
+	HeadSHA string
+	// CreatedAt is the time the merge request was created
+	CreatedAt *time.Time
+	// UpdatedAt is the time the merge request was last updated
+	UpdatedAt *time.Time
+	// MergedAt is the time the merge request was merged, nil if not merged
+	MergedAt *time.Time
+	// Commits is a list of commits of the merge request, only populated by GetMergeRequest
+	Commits []MergeRequestCommit
+	// Approvals is a list of users that approved the merge request, only populated by GetMergeRequest
+	Approvals []User
 	// Repository is the repository of the merge request
 	Repository Repository
 }
 
+type MergeRequestCommit struct {
+	Hash       string     // Hash is the commit sha
+	Message    string     // Message is the full commit message
+	Author     GitAuthor  // Author is the author of the commit
+	AuthoredAt *time.Time // AuthoredAt is the time the commit was authored
+}
+
 type MergeRequestDiff struct {
 	ChangedFiles []MergeRequestFileDiff
+	IsTruncated  bool // IsTruncated is true if the platform did not return all changed files (GitHub lists at most 3000 files)
+}
+
+type ApprovalState struct {
+	IsApproved                bool           // IsApproved is true if all approval requirements are satisfied
+	ApprovedBy                []User         // ApprovedBy is a list of users that approved the merge request
+	RequestedReviewers        []User         // RequestedReviewers is a list of users that were requested to review and did not review yet
+	RequiredApprovals         int            // RequiredApprovals is the number of approvals required to merge
+	ApprovalsLeft             int            // ApprovalsLeft is the number of approvals still required to merge
+	RequiresCodeOwnerApproval bool           // RequiresCodeOwnerApproval is true if the code owners of the changed files need to approve
+	Rules                     []ApprovalRule // Rules is a list of approval rules that apply to the merge request
+}
+
+type ApprovalRule struct {
+	Name              string           // Name is the name of the rule
+	Type              ApprovalRuleType // Type is the type of the rule
+	RequiredApprovals int              // RequiredApprovals is the number of approvals required by this rule
+	ApprovedBy        []User           // ApprovedBy is a list of users that approved in the scope of this rule (GitLab only)
+	EligibleApprovers []User           // EligibleApprovers is a list of users that can approve in the scope of this rule (GitLab only)
+	IsApproved        bool             // IsApproved is true if the rule is satisfied
+}
+
+type Review struct {
+	Event     ReviewEvent     // Event is the review result, e.g. approve or comment only
+	Body      string          // Body is the summary of the review, optional
+	Comments  []ReviewComment // Comments is a list of inline comments
+	CommitSHA string          // CommitSHA is the commit the line numbers refer to, defaults to the head of the merge request (GitHub only, GitLab uses the latest diff)
+}
+
+type ReviewComment struct {
+	Path      string // Path is the path of the file (new path for renamed files)
+	OldPath   string // OldPath is the previous path of renamed files, defaults to Path
+	NewLine   int    // NewLine is the line number in the new version of the file, 0 for removed lines
+	OldLine   int    // OldLine is the line number in the old version of the file, 0 for added lines (set both for unchanged lines)
+	StartLine int    // StartLine is the first line of a multi-line comment on the same side, 0 for single line comments (GitHub only)
+	Body      string // Body is the markdown content of the comment
+}
+
+type MergeRequestCheck struct {
+	Name  string        // Name is the name of the check run, commit status context or job
+	State PipelineState // State is the state of the check
+	URL   string        // URL is the link to the check details
+}
+
+type MergeRequestComment struct {
+	Id        int64      // Id is the unique identifier of the comment
+	Body      string     // Body is the markdown content of the comment
+	Author    User       // Author is the user that created the comment
+	IsSystem  bool       // IsSystem is true for comments generated by the platform (GitLab system notes)
+	CreatedAt *time.Time // CreatedAt is the time the comment was created
+	UpdatedAt *time.Time // UpdatedAt is the time the comment was last updated
 }
 
 type MergeRequestFileDiff struct {
@@ -128,6 +262,23 @@ type MergeRequestFileDiff struct {
 	OldMode   string
 	NewMode   string
 	Diff      string
+
+	IsBinary    bool       // IsBinary is true for binary files, the diff is empty
+	IsTruncated bool       // IsTruncated is true if the diff of the file was omitted because it is too large
+	Hunks       []DiffHunk // Hunks is the parsed structure of Diff, see ParseDiffHunks
+}
+
+type MergeRequestOptions struct {
+	TargetBranch        string                // the target branch, defaults to the default branch of the repository
+	Labels              []string              // labels to assign, replaces existing labels on update
+	Assignees           []string              // usernames of the assignees
+	Reviewers           []string              // usernames of the reviewers, use organization/team to request a team review on GitHub
+	Milestone           string                // title of the milestone
+	IsDraft             bool                  // mark the merge request as draft / work in progress
+	Squash              *bool                 // squash commits on merge (GitLab only, GitHub selects the merge method on merge), defaults to true
+	RemoveSourceBranch  *bool                 // remove the source branch on merge (GitLab only, GitHub uses the repository setting), defaults to true
+	AllowMaintainerEdit *bool                 // allow maintainers of the target repository to push to the source branch
+	AutoMerge           *MergeStrategyOptions // enable auto-merge with the given strategy once all checks passed, not applied to drafts, disabled if nil (GitLab waits for the head pipeline, see ErrPipelineTimeout)
 }
 
 type MergeRequestSearchOptions struct {
@@ -138,6 +289,49 @@ type MergeRequestSearchOptions struct {
 	IsDraft        *bool   // Filter by draft status
 	AuthorId       *int64  // Filter by author user id
 	AuthorUsername *string // Filter by author username
+
+	IncludePipelineState bool // Query the pipeline state and checks of each merge request, requires additional requests per merge request
+}
+
+type Branch struct {
+	// Name is the name of the branch
+	Name string
+	// CommitHash is the commit hash of the branch head
+	CommitHash string
+	// CommitDate is the commit date of the branch head
+	CommitDate *time.Time
+	// IsDefault is true if this is the default branch of the repository
+	IsDefault bool
+	// IsProtected is true if the branch is protected
+	IsProtected bool
+}
+
+type TreeEntry struct {
+	// Path is the path of the entry, relative to the repository root
+	Path string
+	// Name is the file or directory name of the entry
+	Name string
+	// Type is the type of the entry
+	Type TreeEntryType
+	// Mode is the git file mode of the entry (e.g. 100644)
+	Mode string
+	// Size is the size of the blob in bytes (only provided by GitHub)
+	Size int64
+	// SHA is the object hash of the blob or tree
+	SHA string
+}
+
+type File struct {
+	// Path is the path of the file, relative to the repository root
+	Path string
+	// Content is the raw content of the file
+	Content []byte
+	// SHA is the blob hash of the file
+	SHA string
+	// Size is the size of the file in bytes
+	Size int64
+	// Mode is the git file mode of the file (e.g. 100644, 100755, 120000 for symlinks or 160000 for submodules)
+	Mode string
 }
 
 type Tag struct {
@@ -161,14 +355,21 @@ type Release struct {
 }
 
 type MergeStrategyOptions struct {
-	Squash             *bool
-	RemoveSourceBranch *bool
+	Method             MergeMethod // merge method, uses the platform / project default if empty
+	Squash             *bool       // squash commits, deprecated in favor of Method
+	RemoveSourceBranch *bool       // remove the source branch after merging (GitLab only)
+	CommitTitle        string      // title of the merge / squash commit, uses the platform default if empty
+	CommitMessage      string      // message of the merge / squash commit, uses the platform default if empty
+	ExpectedHeadSHA    string      // only merge if the head of the source branch matches this commit sha
+	MergeIfMergeable   bool        // merge immediately if auto-merge is rejected because the merge request is already mergeable (GitHub, e.g. no required checks)
 }
 
 type RepositoryListOpts struct {
-	IncludeBranches   bool
-	IncludeCommitHash bool
-	IncludePlan       bool
+	IncludeBranches          bool
+	IncludeCommitHash        bool
+	IncludePlan              bool
+	IncludeLanguage          bool // query the primary language, if not provided by the list response
+	IncludeMergeRequestCount bool // query the number of open merge requests
 }
 
 type User struct {
@@ -183,6 +384,18 @@ type User struct {
 	GlobalAdministrator bool       `json:"global_administrator"`
 }
 
+type Commit struct {
+	Message  string          // Message is the commit message
+	Paths    []string        // Paths are the files or directories (relative to the repository root) included in the commit, all remaining changes if empty
+	Trailers []CommitTrailer // Trailers are appended to the commit message, e.g. Signed-off-by or Co-authored-by
+	SignOff  bool            // SignOff appends a Signed-off-by trailer for the identity that authors the commit, as required by the Developer Certificate of Origin (DCO)
+}
+
+type CommitTrailer struct {
+	Key   string
+	Value string
+}
+
 type GitAuthor struct {
 	Name  string `yaml:"name"`
 	Email string `yaml:"email"`
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var commitTrailerTokenPattern = regexp.MustCompile(`^[A-Za-z0-9-]+$`)
+
+// knownTrailerKeys are trailers that mark a paragraph as trailer block even if it contains other lines, similar to the git-generated prefixes of git interpret-trailers
+var knownTrailerKeys = []string{"Signed-off-by", "Co-authored-by", "Reviewed-by", "Acked-by", "Tested-by", "Reported-by", "Helped-by", "Change-Id"}
+
+// AssignCommitPaths distributes the changed files across the commits, in order.
+// A file is assigned to the first commit with a matching path (the file itself or a parent directory), a commit without paths takes all remaining files.
+// Files that do not match any commit are not assigned.
+func AssignCommitPaths(changedFiles []string, commits []Commit) [][]string {
+	result := make([][]string, len(commits))
+	assigned := make(map[string]bool)
+
+	for i, commit := range commits {
+		for _, file := range changedFiles {
+			if assigned[file] || !matchesCommitPaths(file, commit.Paths) {
+				continue
+			}
+
+			result[i] = append(result[i], file)
+			assigned[file] = true
+		}
+	}
+
+	return result
+}
+
+func matchesCommitPaths(file string, paths []string) bool {
+	if len(paths) == 0 {
+		return true
+	}
+
+	for _, p := range paths {
+		p = strings.TrimSuffix(strings.TrimPrefix(p, "./"), "/")
+		if file == p || strings.HasPrefix(file, p+"/") || p == "." {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CommitMessage returns the message of the commit including its trailers
+func CommitMessage(commit Commit) string {
+	return AppendCommitTrailers(commit.Message, commit.Trailers)
+}
+
+// AppendCommitTrailers appends the trailers to the commit message, trailers that are already present are skipped.
+// The trailers are added to an existing trailer block or as a new paragraph at the end of the message.
+func AppendCommitTrailers(message string, trailers []CommitTrailer) string {
+	message = strings.TrimRight(message, " \r\n")
+	existing := make(map[string]bool)
+	for _, line := range strings.Split(message, "\n") {
+		existing[strings.TrimSpace(line)] = true
+	}
+
+	var lines []string
+	for _, trailer := range trailers {
+		line := fmt.Sprintf("%s: %s", trailer.Key, trailer.Value)
+		if existing[line] {
+			continue
+		}
+		lines = append(lines, line)
+		existing[line] = true
+	}
+	if len(lines) == 0 {
+		return message
+	}
+
+	separator := "\n\n"
+	if paragraphs := strings.Split(message, "\n\n"); len(paragraphs) > 1 && isTrailerBlock(paragraphs[len(paragraphs)-1]) {
+		separator = "\n"
+	}
+
+	return message + separator + strings.Join(lines, "\n")
+}
+
+// SignOffCommits returns a copy of the commits with a Signed-off-by trailer of the author added to every commit that requests a sign-off
+func SignOffCommits(commits []Commit, author GitAuthor) []Commit {
+	result := slices.Clone(commits)
+	for i, commit := range result {
+		if commit.SignOff {
+			result[i].Trailers = append(slices.Clone(commit.Trailers), SignedOffBy(author))
+		}
+	}
+
+	return result
+}
+
+// RequiresSignOff returns true if any of the commits requests a sign-off
+func RequiresSignOff(commits []Commit) bool {
+	return slices.ContainsFunc(commits, func(c Commit) bool { return c.SignOff })
+}
+
+// SignedOffBy returns a Signed-off-by trailer, as required by the Developer Certificate of Origin (DCO)
+func SignedOffBy(author GitAuthor) CommitTrailer {
+	return CommitTrailer{Key: "Signed-off-by", Value: fmt.Sprintf("%s <%s>", author.Name, author.Email)}
+}
+
+// CoAuthoredBy returns a Co-authored-by trailer
+func CoAuthoredBy(author GitAuthor) CommitTrailer {
+	return CommitTrailer{Key: "Co-authored-by", Value: fmt.Sprintf("%s <%s>", author.Name, author.Email)}
+}
+
+// isTrailerBlock checks if the paragraph is a trailer block, following the rules of git interpret-trailers.
+// A paragraph is a trailer block if all lines are trailers, or if it contains a known trailer and at least 25% of the lines are trailers.
+// Lines starting with whitespace continue the previous trailer.
+func isTrailerBlock(paragraph string) bool {
+	trailerLines, otherLines := 0, 0
+	recognized := false
+	inTrailer := false
+	for _, line := range strings.Split(paragraph, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if inTrailer && (line[0] == ' ' || line[0] == '\t') {
+			continue
+		}
+
+		key, _, found := strings.Cut(line, ":")
+		key = strings.TrimRight(key, " \t")
+		inTrailer = found && commitTrailerTokenPattern.MatchString(key)
+		if inTrailer {
+			trailerLines++
+			recognized = recognized || slices.ContainsFunc(knownTrailerKeys, func(k string) bool { return strings.EqualFold(k, key) })
+		} else if strings.HasPrefix(line, "(cherry picked from commit ") {
+			trailerLines++
+			recognized = true
+		} else {
+			otherLines++
+		}
+	}
+
+	return (trailerLines > 0 && otherLines == 0) || (recognized && trailerLines*3 >= otherLines)
+}
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAssignCommitPaths(t *testing.T) {
+	changedFiles := []string{"go.mod", "go.sum", "module-a/main.go", "module-b/main.go", "README.md"}
+
+	testCases := []struct {
+		name     string
+		commits  []Commit
+		expected [][]string
+	}{
+		{"single commit", []Commit{{Message: "all"}}, [][]string{changedFiles}},
+		{"commit per module", []Commit{{Paths: []string{"module-a"}}, {Paths: []string{"module-b/"}}}, [][]string{{"module-a/main.go"}, {"module-b/main.go"}}},
+		{"remaining files", []Commit{{Paths: []string{"go.mod", "go.sum"}}, {}}, [][]string{{"go.mod", "go.sum"}, {"module-a/main.go", "module-b/main.go", "README.md"}}},
+		{"first match wins", []Commit{{Paths: []string{"."}}, {Paths: []string{"README.md"}}}, [][]string{changedFiles, nil}},
+	}
+
+	for _, tc := range testCases {
+		result := AssignCommitPaths(changedFiles, tc.commits)
+		if !reflect.DeepEqual(result, tc.expected) {
+			t.Errorf("For case %s, expected %v, but got %v", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestAppendCommitTrailers(t *testing.T) {
+	signOff := SignedOffBy(GitAuthor{Name: "vcs-app", Email: "vcs-app@localhost"})
+	coAuthor := CoAuthoredBy(GitAuthor{Name: "Jane Doe", Email: "jane@example.com"})
+
+	testCases := []struct {
+		name     string
+		message  string
+		trailers []CommitTrailer
+		expected string
+	}{
+		{"no trailers", "chore: update", nil, "chore: update"},
+		{"subject only", "chore: update\n", []CommitTrailer{signOff}, "chore: update\n\nSigned-off-by: vcs-app <vcs-app@localhost>"},
+		{"with body", "chore: update\n\nbody", []CommitTrailer{signOff, coAuthor}, "chore: update\n\nbody\n\nSigned-off-by: vcs-app <vcs-app@localhost>\nCo-authored-by: Jane Doe <jane@example.com>"},
+		{"existing trailer block", "chore: update\n\nRefs: #123", []CommitTrailer{signOff}, "chore: update\n\nRefs: #123\nSigned-off-by: vcs-app <vcs-app@localhost>"},
+		{"already present", "chore: update\n\nSigned-off-by: vcs-app <vcs-app@localhost>", []CommitTrailer{signOff}, "chore: update\n\nSigned-off-by: vcs-app <vcs-app@localhost>"},
+		{"custom key", "chore: update", []CommitTrailer{{Key: "Change-Id", Value: "I123"}}, "chore: update\n\nChange-Id: I123"},
+		{"prose ending in key value", "chore: update\n\nUpdates the dependencies.\nNote: requires a restart", []CommitTrailer{signOff}, "chore: update\n\nUpdates the dependencies.\nNote: requires a restart\n\nSigned-off-by: vcs-app <vcs-app@localhost>"},
+		{"known trailer after prose", "chore: update\n\nUpdates the dependencies.\nCo-authored-by: Jane Doe <jane@example.com>", []CommitTrailer{signOff}, "chore: update\n\nUpdates the dependencies.\nCo-authored-by: Jane Doe <jane@example.com>\nSigned-off-by: vcs-app <vcs-app@localhost>"},
+	}
+
+	for _, tc := range testCases {
+		result := AppendCommitTrailers(tc.message, tc.trailers)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %q, but got %q", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestIsTrailerBlock(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected bool
+	}{
+		{"single trailer", "Refs: #123", true},
+		{"multiple trailers", "Refs: #123\nSigned-off-by: vcs-app <vcs-app@localhost>", true},
+		{"continuation line", "Refs: #123\n  #456\nSigned-off-by: vcs-app <vcs-app@localhost>", true},
+		{"space in token", "See the docs: https://example.com", false},
+		{"prose ending in key value", "Updates the dependencies.\nNote: requires a restart", false},
+		{"prose with known trailer", "Updates the dependencies.\nSigned-off-by: vcs-app <vcs-app@localhost>", true},
+		{"mostly prose with known trailer", "Updates the dependencies.\nThe lockfile was regenerated.\nAll tests pass.\nA restart is required.\nSigned-off-by: vcs-app <vcs-app@localhost>", false},
+		{"cherry pick", "Refs: #123\n(cherry picked from commit 4b825dc)", true},
+		{"prose", "Updates the dependencies.", false},
+	}
+
+	for _, tc := range testCases {
+		result := isTrailerBlock(tc.input)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %t, but got %t", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestSignOffCommits(t *testing.T) {
+	author := GitAuthor{Name: "vcs-app", Email: "vcs-app@localhost"}
+	commits := []Commit{
+		{Message: "chore: first", SignOff: true, Trailers: []CommitTrailer{{Key: "Refs", Value: "#123"}}},
+		{Message: "chore: second"},
+	}
+
+	result := SignOffCommits(commits, author)
+	if CommitMessage(result[0]) != "chore: first\n\nRefs: #123\nSigned-off-by: vcs-app <vcs-app@localhost>" {
+		t.Errorf("expected signed off commit, but got %q", CommitMessage(result[0]))
+	}
+	if CommitMessage(result[1]) != "chore: second" {
+		t.Errorf("expected commit without sign-off, but got %q", CommitMessage(result[1]))
+	}
+	if len(commits[0].Trailers) != 1 {
+		t.Errorf("expected input commits to be unchanged, but got %+v", commits[0].Trailers)
+	}
+}
@@ -1,5 +1,21 @@
 package api
 
+type RepositoryVisibility string
+
+const (
+	RepositoryVisibilityPublic   RepositoryVisibility = "public"
+	RepositoryVisibilityInternal RepositoryVisibility = "internal"
+	RepositoryVisibilityPrivate  RepositoryVisibility = "private"
+)
+
+type TreeEntryType string
+
+const (
+	TreeEntryTypeBlob   TreeEntryType = "blob"   // file or symlink
+	TreeEntryTypeTree   TreeEntryType = "tree"   // directory
+	TreeEntryTypeCommit TreeEntryType = "commit" // submodule
+)
+
 type MergeRequestState string
 
 const (
@@ -7,6 +23,42 @@ const (
 	MergeRequestStateClosed MergeRequestState = "closed"
 )
 
+type MergeMethod string
+
+const (
+	MergeMethodMerge       MergeMethod = "merge"        // merge commit
+	MergeMethodSquash      MergeMethod = "squash"       // squash all commits into a single commit
+	MergeMethodRebase      MergeMethod = "rebase"       // rebase the commits onto the target branch (GitLab: rebase_merge or ff project merge method)
+	MergeMethodFastForward MergeMethod = "fast_forward" // fast-forward the target branch without a merge commit (GitLab only, requires the ff project merge method)
+)
+
+type ApprovalRuleType string
+
+const (
+	ApprovalRuleTypeRegular     ApprovalRuleType = "regular"      // a number of approvals from eligible users (GitHub: branch protection / rulesets)
+	ApprovalRuleTypeCodeOwner   ApprovalRuleType = "code_owner"   // approval by the code owners of the changed files
+	ApprovalRuleTypeAnyApprover ApprovalRuleType = "any_approver" // a number of approvals from any user with write access (GitLab only)
+	ApprovalRuleTypeReport      ApprovalRuleType = "report"       // approval required by a security / license report (GitLab only)
+)
+
+type ReviewEvent string
+
+const (
+	ReviewEventApprove        ReviewEvent = "approve"
+	ReviewEventRequestChanges ReviewEvent = "request_changes" // GitLab revokes the approval of the current user
+	ReviewEventComment        ReviewEvent = "comment"         // comments only, without approving or requesting changes
+)
+
+type MergeRequestOutcome string
+
+const (
+	MergeRequestOutcomeCreated   MergeRequestOutcome = "created"
+	MergeRequestOutcomeUpdated   MergeRequestOutcome = "updated"
+	MergeRequestOutcomeUnchanged MergeRequestOutcome = "unchanged"
+	MergeRequestOutcomeSkipped   MergeRequestOutcome = "skipped" // no changes, merge request was not created or updated
+	MergeRequestOutcomeClosed    MergeRequestOutcome = "closed"  // no changes remain, the existing merge request was closed
+)
+
 type PipelineState string
 
 const (
@@ -0,0 +1,86 @@
+package api
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@ ?(.*)$`)
+
+type DiffLineType string
+
+const (
+	DiffLineTypeContext DiffLineType = "context"
+	DiffLineTypeAdded   DiffLineType = "added"
+	DiffLineTypeRemoved DiffLineType = "removed"
+)
+
+type DiffHunk struct {
+	OldStart int        // OldStart is the first line of the hunk in the old version of the file
+	OldLines int        // OldLines is the number of lines of the hunk in the old version of the file
+	NewStart int        // NewStart is the first line of the hunk in the new version of the file
+	NewLines int        // NewLines is the number of lines of the hunk in the new version of the file
+	Header   string     // Header is the section heading after the line ranges, e.g. the enclosing function
+	Lines    []DiffLine // Lines are the lines of the hunk
+}
+
+type DiffLine struct {
+	Type    DiffLineType // Type is the type of change
+	OldLine int          // OldLine is the line number in the old version of the file, 0 for added lines
+	NewLine int          // NewLine is the line number in the new version of the file, 0 for removed lines
+	Content string       // Content is the content of the line without the leading +, - or space
+}
+
+// ParseDiffHunks parses the hunks of a unified diff of a single file, file headers (---, +++) are skipped
+func ParseDiffHunks(diff string) []DiffHunk {
+	var result []DiffHunk
+	var hunk *DiffHunk
+	var oldLine, newLine int
+
+	for _, line := range strings.Split(UnifyLineEndings(diff), "\n") {
+		if match := hunkHeaderPattern.FindStringSubmatch(line); match != nil {
+			result = append(result, DiffHunk{
+				OldStart: atoiOrDefault(match[1], 0),
+				OldLines: atoiOrDefault(match[2], 1),
+				NewStart: atoiOrDefault(match[3], 0),
+				NewLines: atoiOrDefault(match[4], 1),
+				Header:   match[5],
+			})
+			hunk = &result[len(result)-1]
+			oldLine = hunk.OldStart
+			newLine = hunk.NewStart
+			continue
+		}
+		if hunk == nil || line == "" {
+			continue
+		}
+
+		switch line[0] {
+		case '+':
+			hunk.Lines = append(hunk.Lines, DiffLine{Type: DiffLineTypeAdded, NewLine: newLine, Content: line[1:]})
+			newLine++
+		case '-':
+			hunk.Lines = append(hunk.Lines, DiffLine{Type: DiffLineTypeRemoved, OldLine: oldLine, Content: line[1:]})
+			oldLine++
+		case ' ':
+			hunk.Lines = append(hunk.Lines, DiffLine{Type: DiffLineTypeContext, OldLine: oldLine, NewLine: newLine, Content: line[1:]})
+			oldLine++
+			newLine++
+		}
+	}
+
+	return result
+}
+
+func atoiOrDefault(s string, defaultValue int) int {
+	if s == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(s)
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
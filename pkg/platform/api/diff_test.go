@@ -0,0 +1,39 @@
+package api
+
+import (
+	"testing"
+)
+
+func TestParseDiffHunks(t *testing.T) {
+	diff := "--- a/main.go\n+++ b/main.go\n@@ -1,3 +1,4 @@ package main\n import \"fmt\"\n-var a = 1\n+var a = 2\n+var b = 3\n \n@@ -10 +11 @@\n-old\n+new\n\\ No newline at end of file\n"
+
+	hunks := ParseDiffHunks(diff)
+	if len(hunks) != 2 {
+		t.Fatalf("expected 2 hunks, but got %d", len(hunks))
+	}
+
+	first := hunks[0]
+	if first.OldStart != 1 || first.OldLines != 3 || first.NewStart != 1 || first.NewLines != 4 || first.Header != "package main" {
+		t.Errorf("unexpected hunk header: %+v", first)
+	}
+	expectedLines := []DiffLine{
+		{Type: DiffLineTypeContext, OldLine: 1, NewLine: 1, Content: "import \"fmt\""},
+		{Type: DiffLineTypeRemoved, OldLine: 2, Content: "var a = 1"},
+		{Type: DiffLineTypeAdded, NewLine: 2, Content: "var a = 2"},
+		{Type: DiffLineTypeAdded, NewLine: 3, Content: "var b = 3"},
+		{Type: DiffLineTypeContext, OldLine: 3, NewLine: 4, Content: ""},
+	}
+	if len(first.Lines) != len(expectedLines) {
+		t.Fatalf("expected %d lines, but got %d", len(expectedLines), len(first.Lines))
+	}
+	for i, line := range first.Lines {
+		if line != expectedLines[i] {
+			t.Errorf("For line %d, expected %+v, but got %+v", i, expectedLines[i], line)
+		}
+	}
+
+	second := hunks[1]
+	if second.OldStart != 10 || second.OldLines != 1 || second.NewStart != 11 || second.NewLines != 1 || len(second.Lines) != 2 {
+		t.Errorf("unexpected hunk: %+v", second)
+	}
+}
@@ -0,0 +1,14 @@
+package api
+
+import (
+	"errors"
+)
+
+// ErrNotFound is returned if the requested resource (e.g. a file) does not exist
+var ErrNotFound = errors.New("not found")
+
+// ErrTruncated is returned together with the partial result if a listing exceeds the limits of the platform api
+var ErrTruncated = errors.New("result truncated")
+
+// ErrPipelineTimeout is returned if the pipeline of a merge request did not start in time, e.g. to enable auto-merge
+var ErrPipelineTimeout = errors.New("timed out waiting for pipeline")
@@ -1,11 +1,159 @@
 package api
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
 )
 
+// binaryDetectionLimit is the number of bytes inspected to detect binary content, same as git
+const binaryDetectionLimit = 8000
+
 // UnifyLineEndings replaces all line endings with LF
 func UnifyLineEndings(str string) string {
 	str = strings.ReplaceAll(str, "\r\n", "\n")
 	return str
 }
+
+// IsBinary returns true if the content contains a NUL byte within the first 8000 bytes, which is the heuristic used by git
+func IsBinary(content []byte) bool {
+	return bytes.IndexByte(content[:min(len(content), binaryDetectionLimit)], 0) != -1
+}
+
+// LineEndingPolicy decides if the line endings of a file are normalized to LF when committing, based on the .gitattributes text, eol and binary attributes and core.autocrlf
+type LineEndingPolicy struct {
+	patterns   []gitattributes.MatchAttribute
+	autoCRLF   bool
+	indexHasCR func(path string) bool // indexHasCR reports if the blob in the index contains CR, nil if there is no index
+}
+
+// NewLineEndingPolicy reads the .gitattributes files and the core.autocrlf setting of the repository in dir
+func NewLineEndingPolicy(dir string) (LineEndingPolicy, error) {
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		return LineEndingPolicy{}, fmt.Errorf("failed to open repository: %w", err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return LineEndingPolicy{}, fmt.Errorf("failed to get worktree: %w", err)
+	}
+	cfg, err := r.ConfigScoped(config.GlobalScope)
+	if err != nil {
+		return LineEndingPolicy{}, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// .gitattributes in the worktree, followed by .git/info/attributes which takes precedence
+	patterns, err := gitattributes.ReadPatterns(w.Filesystem, nil)
+	if err != nil {
+		return LineEndingPolicy{}, fmt.Errorf("failed to read .gitattributes: %w", err)
+	}
+	if info, readErr := os.ReadFile(filepath.Join(dir, ".git", "info", "attributes")); readErr == nil {
+		infoPatterns, err := gitattributes.ReadAttributes(bytes.NewReader(info), nil, true)
+		if err != nil {
+			return LineEndingPolicy{}, fmt.Errorf("failed to read info/attributes: %w", err)
+		}
+		patterns = append(patterns, infoPatterns...)
+	}
+
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return LineEndingPolicy{}, fmt.Errorf("failed to read index: %w", err)
+	}
+	indexHasCR := func(path string) bool {
+		entry, err := idx.Entry(path)
+		if err != nil {
+			return false
+		}
+		blob, err := r.BlobObject(entry.Hash)
+		if err != nil {
+			return false
+		}
+		reader, err := blob.Reader()
+		if err != nil {
+			return false
+		}
+		defer reader.Close()
+		content, err := io.ReadAll(reader)
+		return err == nil && bytes.IndexByte(content, '\r') != -1
+	}
+
+	autoCRLF := cfg.Raw.Section("core").Option("autocrlf")
+	return newLineEndingPolicy(patterns, autoCRLF == "true" || autoCRLF == "input", indexHasCR), nil
+}
+
+func newLineEndingPolicy(patterns []gitattributes.MatchAttribute, autoCRLF bool, indexHasCR func(path string) bool) LineEndingPolicy {
+	return LineEndingPolicy{
+		patterns:   patterns,
+		autoCRLF:   autoCRLF,
+		indexHasCR: indexHasCR,
+	}
+}
+
+// Normalize returns the content that should be committed for the file at path (relative to the repository root)
+func (p LineEndingPolicy) Normalize(path string, content []byte) []byte {
+	if !p.shouldNormalize(path, content) {
+		return content
+	}
+
+	return bytes.ReplaceAll(content, []byte("\r\n"), []byte("\n"))
+}
+
+func (p LineEndingPolicy) shouldNormalize(path string, content []byte) bool {
+	pathParts := strings.Split(path, "/")
+
+	// binary or -text disables the conversion
+	if attr, ok := p.attribute(pathParts, "binary"); ok && attr.IsSet() {
+		return false
+	}
+	if attr, ok := p.attribute(pathParts, "text"); ok {
+		switch {
+		case attr.IsSet():
+			return true
+		case attr.IsUnset():
+			return false
+		case attr.IsValueSet() && attr.Value() == "auto":
+			return p.autoNormalize(path, content)
+		}
+	}
+
+	// eol implies text
+	if attr, ok := p.attribute(pathParts, "eol"); ok && attr.IsValueSet() {
+		return true
+	}
+
+	// core.autocrlf true / input behaves like text=auto for files without text attribute
+	return p.autoCRLF && p.autoNormalize(path, content)
+}
+
+// autoNormalize decides if text=auto / core.autocrlf converts the file, skipping binary content and files that already have CR in the index (safer autocrlf, same as git)
+func (p LineEndingPolicy) autoNormalize(path string, content []byte) bool {
+	if IsBinary(content) {
+		return false
+	}
+
+	return p.indexHasCR == nil || !p.indexHasCR(path)
+}
+
+// attribute returns the attribute of the highest priority pattern matching the path
+func (p LineEndingPolicy) attribute(path []string, name string) (gitattributes.Attribute, bool) {
+	for i := len(p.patterns) - 1; i >= 0; i-- {
+		if p.patterns[i].Pattern == nil || !p.patterns[i].Pattern.Match(path) {
+			continue
+		}
+
+		for j := len(p.patterns[i].Attributes) - 1; j >= 0; j-- {
+			if p.patterns[i].Attributes[j].Name() == name {
+				return p.patterns[i].Attributes[j], true
+			}
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,106 @@
+package api
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/gitattributes"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestIsBinary(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []byte
+		expected bool
+	}{
+		{"empty", []byte{}, false},
+		{"text", []byte("hello\r\nworld\n"), false},
+		{"utf-8 text", []byte("größe"), false},
+		{"png header", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00}, true},
+		{"nul after detection limit", append(bytes.Repeat([]byte("a"), 8000), 0), false},
+	}
+
+	for _, tc := range testCases {
+		result := IsBinary(tc.input)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %t, but got %t", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestLineEndingPolicyNormalize(t *testing.T) {
+	testCases := []struct {
+		name       string
+		attributes []string
+		autoCRLF   bool
+		path       string
+		input      string
+		expected   string
+	}{
+		{"no attributes", nil, false, "build.bat", "a\r\nb\r\n", "a\r\nb\r\n"},
+		{"autocrlf", nil, true, "main.go", "a\r\nb\r\n", "a\nb\n"},
+		{"autocrlf binary content", nil, true, "image.png", "a\r\n\x00", "a\r\n\x00"},
+		{"text", []string{"*.go text"}, false, "pkg/main.go", "a\r\nb\r\n", "a\nb\n"},
+		{"text auto", []string{"* text=auto"}, false, "main.go", "a\r\nb\r\n", "a\nb\n"},
+		{"unset text overrides autocrlf", []string{"*.bat -text"}, true, "build.bat", "a\r\nb\r\n", "a\r\nb\r\n"},
+		{"unset text overrides text auto", []string{"* text=auto", "*.sln -text"}, false, "app.sln", "a\r\nb\r\n", "a\r\nb\r\n"},
+		{"binary", []string{"*.dat binary"}, true, "data.dat", "a\r\nb\r\n", "a\r\nb\r\n"},
+		{"eol implies text", []string{"*.sh eol=lf"}, false, "run.sh", "a\r\nb\r\n", "a\nb\n"},
+	}
+
+	for _, tc := range testCases {
+		var patterns []gitattributes.MatchAttribute
+		for _, line := range tc.attributes {
+			pattern, _ := gitattributes.ParseAttributesLine(line, nil, true)
+			patterns = append(patterns, pattern)
+		}
+
+		result := string(newLineEndingPolicy(patterns, tc.autoCRLF, nil).Normalize(tc.path, []byte(tc.input)))
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %q, but got %q", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestLineEndingPolicyCRLFInIndex(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	w, _ := r.Worktree()
+	_ = os.WriteFile(filepath.Join(dir, ".gitattributes"), []byte("* text=auto\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "legacy.sln"), []byte("a\r\nb\r\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "main.go"), []byte("a\nb\n"), 0644)
+	_, _ = w.Add(".")
+	if _, err = w.Commit("initial", &git.CommitOptions{Author: &object.Signature{Name: "vcs-app", Email: "vcs-app@localhost"}}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	policy, err := NewLineEndingPolicy(dir)
+	if err != nil {
+		t.Fatalf("failed to read line ending policy: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		path     string
+		input    string
+		expected string
+	}{
+		{"crlf in index", "legacy.sln", "a\r\nb\r\nc\r\n", "a\r\nb\r\nc\r\n"},
+		{"lf in index", "main.go", "a\r\nb\r\n", "a\nb\n"},
+		{"new file", "new.go", "a\r\nb\r\n", "a\nb\n"},
+	}
+
+	for _, tc := range testCases {
+		result := string(policy.Normalize(tc.path, []byte(tc.input)))
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %q, but got %q", tc.name, tc.expected, result)
+		}
+	}
+}
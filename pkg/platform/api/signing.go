@@ -0,0 +1,151 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/go-git/go-git/v5"
+	"golang.org/x/crypto/ssh"
+)
+
+type SigningFormat string
+
+const (
+	SigningFormatGPG SigningFormat = "gpg"
+	SigningFormatSSH SigningFormat = "ssh"
+)
+
+type SigningConfig struct {
+	Format     SigningFormat // Format is the signature format, gpg or ssh (defaults to gpg)
+	Key        string        // Key is the armored gpg private key or the openssh private key, commits are not signed if empty
+	Passphrase string        // Passphrase is used to decrypt the key, if it is encrypted
+}
+
+const sshSignatureNamespace = "git"
+
+// NewCommitSigner creates a signer for locally created commits from the signing configuration, returns nil if no key is configured
+func NewCommitSigner(config SigningConfig) (git.Signer, error) {
+	if config.Key == "" {
+		return nil, nil
+	}
+
+	switch config.Format {
+	case SigningFormatGPG, "":
+		return newGPGSigner(config.Key, config.Passphrase)
+	case SigningFormatSSH:
+		return newSSHSigner(config.Key, config.Passphrase)
+	default:
+		return nil, fmt.Errorf("unsupported signing format: %s", config.Format)
+	}
+}
+
+type gpgSigner struct {
+	entity *openpgp.Entity
+}
+
+func newGPGSigner(key string, passphrase string) (git.Signer, error) {
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gpg key: %w", err)
+	}
+	if len(entities) == 0 || entities[0].PrivateKey == nil {
+		return nil, fmt.Errorf("failed to read gpg key: no private key found")
+	}
+
+	entity := entities[0]
+	if entity.PrivateKey.Encrypted {
+		if err = entity.DecryptPrivateKeys([]byte(passphrase)); err != nil {
+			return nil, fmt.Errorf("failed to decrypt gpg key: %w", err)
+		}
+	}
+
+	return gpgSigner{entity: entity}, nil
+}
+
+func (s gpgSigner) Sign(message io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&buf, s.entity, message, nil); err != nil {
+		return nil, fmt.Errorf("failed to create gpg signature: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+type sshSigner struct {
+	signer ssh.Signer
+}
+
+func newSSHSigner(key string, passphrase string) (git.Signer, error) {
+	var signer ssh.Signer
+	var err error
+	if passphrase != "" {
+		signer, err = ssh.ParsePrivateKeyWithPassphrase([]byte(key), []byte(passphrase))
+	} else {
+		signer, err = ssh.ParsePrivateKey([]byte(key))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ssh key: %w", err)
+	}
+
+	return sshSigner{signer: signer}, nil
+}
+
+// Sign creates an armored signature in the SSHSIG format, see https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.sshsig
+func (s sshSigner) Sign(message io.Reader) ([]byte, error) {
+	h := sha512.New()
+	if _, err := io.Copy(h, message); err != nil {
+		return nil, fmt.Errorf("failed to hash message: %w", err)
+	}
+
+	signedData := ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sshSignatureNamespace, "", "sha512", h.Sum(nil)})
+
+	var signature *ssh.Signature
+	var err error
+	data := append([]byte("SSHSIG"), signedData...)
+	if algorithmSigner, ok := s.signer.(ssh.AlgorithmSigner); ok && s.signer.PublicKey().Type() == ssh.KeyAlgoRSA {
+		signature, err = algorithmSigner.SignWithAlgorithm(rand.Reader, data, ssh.KeyAlgoRSASHA512)
+	} else {
+		signature, err = s.signer.Sign(rand.Reader, data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ssh signature: %w", err)
+	}
+
+	blob := ssh.Marshal(struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}{1, s.signer.PublicKey().Marshal(), sshSignatureNamespace, "", "sha512", ssh.Marshal(signature)})
+
+	return armorSSHSignature(append([]byte("SSHSIG"), blob...)), nil
+}
+
+// armorSSHSignature encodes the signature blob as base64 wrapped at 70 characters
+func armorSSHSignature(blob []byte) []byte {
+	encoded := base64.StdEncoding.EncodeToString(blob)
+
+	var buf bytes.Buffer
+	buf.WriteString("-----BEGIN SSH SIGNATURE-----\n")
+	for len(encoded) > 70 {
+		buf.WriteString(encoded[:70] + "\n")
+		encoded = encoded[70:]
+	}
+	buf.WriteString(encoded + "\n")
+	buf.WriteString("-----END SSH SIGNATURE-----")
+
+	return buf.Bytes()
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNewCommitSigner(t *testing.T) {
+	testCases := []struct {
+		name        string
+		input       SigningConfig
+		expectedNil bool
+		expectedErr bool
+	}{
+		{"no key", SigningConfig{}, true, false},
+		{"invalid gpg key", SigningConfig{Format: SigningFormatGPG, Key: "invalid"}, true, true},
+		{"invalid ssh key", SigningConfig{Format: SigningFormatSSH, Key: "invalid"}, true, true},
+		{"unsupported format", SigningConfig{Format: "x509", Key: "invalid"}, true, true},
+	}
+
+	for _, tc := range testCases {
+		signer, err := NewCommitSigner(tc.input)
+		if (signer == nil) != tc.expectedNil || (err != nil) != tc.expectedErr {
+			t.Errorf("For case %s, expected nil signer %t and error %t, but got %v and %v", tc.name, tc.expectedNil, tc.expectedErr, signer, err)
+		}
+	}
+}
+
+func TestSSHSignerSign(t *testing.T) {
+	_, privateKey, _ := ed25519.GenerateKey(rand.Reader)
+	block, _ := ssh.MarshalPrivateKey(privateKey, "")
+	signer, err := NewCommitSigner(SigningConfig{Format: SigningFormatSSH, Key: string(pem.EncodeToMemory(block))})
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+
+	message := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\n\ncommit message\n"
+	armored, err := signer.Sign(strings.NewReader(message))
+	if err != nil {
+		t.Fatalf("failed to sign message: %v", err)
+	}
+
+	// decode armored signature
+	lines := strings.Split(string(armored), "\n")
+	if lines[0] != "-----BEGIN SSH SIGNATURE-----" || lines[len(lines)-1] != "-----END SSH SIGNATURE-----" {
+		t.Fatalf("expected armored ssh signature, but got %s", armored)
+	}
+	blob, err := base64.StdEncoding.DecodeString(strings.Join(lines[1:len(lines)-1], ""))
+	if err != nil || !strings.HasPrefix(string(blob), "SSHSIG") {
+		t.Fatalf("expected SSHSIG blob, but got %v", err)
+	}
+	var sig struct {
+		Version       uint32
+		PublicKey     []byte
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Signature     []byte
+	}
+	if err = ssh.Unmarshal(blob[6:], &sig); err != nil {
+		t.Fatalf("failed to unmarshal signature: %v", err)
+	}
+
+	// verify signature
+	publicKey, _ := ssh.ParsePublicKey(sig.PublicKey)
+	var signature ssh.Signature
+	_ = ssh.Unmarshal(sig.Signature, &signature)
+	hash := sha512.Sum512([]byte(message))
+	signedData := ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{"git", "", "sha512", hash[:]})
+	if err = publicKey.Verify(append([]byte("SSHSIG"), signedData...), &signature); err != nil {
+		t.Errorf("expected valid signature, but got %v", err)
+	}
+}
@@ -1,11 +1,17 @@
 package api
 
 import (
+	"fmt"
 	"net/url"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
+var stickyCommentKeyPattern = regexp.MustCompile(`<!--vcs-sticky-comment:(.*?)-->`)
+var mergeRequestKeyPattern = regexp.MustCompile(`<!--vcs-merge-request-key:(.*?)-->`)
+
 // GetServerIdFromCloneURL returns the server id from a clone / remote url
 func GetServerIdFromCloneURL(repo string) string {
 	u, err := url.Parse(repo)
@@ -20,6 +26,30 @@ func GetServerIdFromCloneURL(repo string) string {
 	return Slugify(u.Hostname())
 }
 
+// ParseRepositoryReference parses a repository reference, which can be a numeric id, a path (e.g. organization/repo) or a https / ssh clone url.
+// Returns the id if the reference is numeric and the path of the repository, numeric references are returned as path as well, since they can also be a path.
+func ParseRepositoryReference(ref string) (int64, string) {
+	ref = strings.TrimSpace(ref)
+	if id, err := strconv.ParseInt(ref, 10, 64); err == nil {
+		return id, ref
+	}
+
+	path := ref
+	if strings.Contains(ref, "://") {
+		u, err := url.Parse(ref)
+		if err == nil {
+			path = u.Path
+		}
+	} else if at := strings.Index(ref, "@"); at >= 0 && strings.Contains(ref[at:], ":") {
+		// scp-like ssh url, e.g. git@github.com:organization/repo.git
+		path = ref[strings.Index(ref[at:], ":")+at+1:]
+	}
+
+	path = strings.Trim(path, "/")
+	path = strings.TrimSuffix(path, ".git")
+	return 0, path
+}
+
 func Slugify(s string) string {
 	regExp := regexp.MustCompile("[^a-z0-9]+")
 	s = strings.ToLower(strings.TrimSpace(s))
@@ -27,3 +57,163 @@ func Slugify(s string) string {
 	s = strings.Trim(s, "-")
 	return s
 }
+
+// IsMergeRequestUpToDate checks if the merge request already matches the requested title, description and options.
+// Requested reviewers must be part of the merge request reviewers, additional reviewers are ignored.
+// Squash, RemoveSourceBranch and AllowMaintainerEdit are only compared if the platform reports them.
+func IsMergeRequestUpToDate(mr MergeRequest, title string, description string, options MergeRequestOptions) bool {
+	if mr.Title != title || mr.Description != description || mr.IsDraft != options.IsDraft {
+		return false
+	}
+	if options.TargetBranch != "" && mr.TargetBranch != options.TargetBranch {
+		return false
+	}
+	if options.Labels != nil && !equalIgnoreOrder(mr.Labels, options.Labels) {
+		return false
+	}
+	if options.Assignees != nil {
+		var assignees []string
+		for _, u := range mr.Assignees {
+			assignees = append(assignees, u.Username)
+		}
+		if !equalIgnoreOrder(assignees, options.Assignees) {
+			return false
+		}
+	}
+	if options.Milestone != "" && mr.Milestone != options.Milestone {
+		return false
+	}
+	if options.Reviewers != nil {
+		var reviewers []string
+		for _, u := range mr.Reviewers {
+			reviewers = append(reviewers, u.Username)
+		}
+		for _, reviewer := range options.Reviewers {
+			if !slices.Contains(reviewers, reviewer) {
+				return false
+			}
+		}
+	}
+	if !equalIfSet(mr.Squash, options.Squash) || !equalIfSet(mr.RemoveSourceBranch, options.RemoveSourceBranch) || !equalIfSet(mr.AllowMaintainerEdit, options.AllowMaintainerEdit) {
+		return false
+	}
+	if options.AutoMerge != nil && !options.IsDraft && !mr.IsAutoMergeEnabled {
+		return false
+	}
+
+	return true
+}
+
+// equalIfSet returns false if both values are set and differ
+func equalIfSet(actual *bool, expected *bool) bool {
+	return actual == nil || expected == nil || *actual == *expected
+}
+
+func equalIgnoreOrder(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	a = slices.Clone(a)
+	b = slices.Clone(b)
+	slices.Sort(a)
+	slices.Sort(b)
+	return slices.Equal(a, b)
+}
+
+// MergeRequestKeyMarker returns the hidden marker that is appended to merge request descriptions to find them by key
+func MergeRequestKeyMarker(key string) string {
+	return fmt.Sprintf("<!--vcs-merge-request-key:%s-->", key)
+}
+
+// MergeRequestKey extracts the merge request key from a description, returns an empty string if no key is present
+func MergeRequestKey(description string) string {
+	match := mergeRequestKeyPattern.FindStringSubmatch(description)
+	if match == nil {
+		return ""
+	}
+
+	return match[1]
+}
+
+// StickyCommentMarker returns the hidden marker that is appended to sticky comments to find them by key
+func StickyCommentMarker(key string) string {
+	return fmt.Sprintf("<!--vcs-sticky-comment:%s-->", key)
+}
+
+// FindStickyComment returns the first comment containing the sticky comment marker for the given key, nil if no comment matches
+func FindStickyComment(comments []MergeRequestComment, key string) *MergeRequestComment {
+	for i, comment := range comments {
+		match := stickyCommentKeyPattern.FindStringSubmatch(comment.Body)
+		if match != nil && match[1] == key {
+			return &comments[i]
+		}
+	}
+
+	return nil
+}
+
+// UpsertStickyComment creates a comment identified by key or updates it in place if it already exists, the comment is left untouched if the body did not change
+func UpsertStickyComment(platform Platform, repo Repository, mergeRequest MergeRequest, key string, body string) (MergeRequestComment, error) {
+	body = body + "\n" + StickyCommentMarker(key)
+
+	comments, err := platform.MergeRequestComments(repo, mergeRequest)
+	if err != nil {
+		return MergeRequestComment{}, err
+	}
+
+	existing := FindStickyComment(comments, key)
+	if existing == nil {
+		return platform.CreateMergeRequestComment(repo, mergeRequest, body)
+	}
+	if existing.Body == body {
+		return *existing, nil
+	}
+
+	return platform.UpdateMergeRequestComment(repo, mergeRequest, existing.Id, body)
+}
+
+// AggregatePipelineState combines the states of all checks into a single pipeline state.
+// A single failed check fails the pipeline, otherwise the pipeline is running / pending until all checks are completed.
+func AggregatePipelineState(checks []MergeRequestCheck) PipelineState {
+	if len(checks) == 0 {
+		return PipelineStateUnknown
+	}
+
+	states := make(map[PipelineState]bool)
+	for _, check := range checks {
+		states[check.State] = true
+	}
+
+	switch {
+	case states[PipelineStateFailed]:
+		return PipelineStateFailed
+	case states[PipelineStateCanceled]:
+		return PipelineStateCanceled
+	case states[PipelineStateRunning]:
+		return PipelineStateRunning
+	case states[PipelineStatePending] || states[PipelineStateCreated] || states[PipelineStateWaitingForResource] || states[PipelineStatePreparing] || states[PipelineStateScheduled]:
+		return PipelineStatePending
+	case states[PipelineStateManual]:
+		return PipelineStateManual
+	case states[PipelineStateSuccess]:
+		return PipelineStateSuccess
+	case states[PipelineStateSkipped]:
+		return PipelineStateSkipped
+	}
+
+	return PipelineStateUnknown
+}
+
+// ResolveMergeMethod returns the requested merge method, falls back to squash if the deprecated Squash option is set.
+// Returns an empty string if the platform / project default should be used.
+func ResolveMergeMethod(options MergeStrategyOptions) MergeMethod {
+	if options.Method != "" {
+		return options.Method
+	}
+	if options.Squash != nil && *options.Squash {
+		return MergeMethodSquash
+	}
+
+	return ""
+}
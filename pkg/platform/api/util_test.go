@@ -2,6 +2,8 @@ package api
 
 import (
 	"testing"
+
+	"github.com/cidverse/go-ptr"
 )
 
 func TestGetServerIdFromCloneURL(t *testing.T) {
@@ -25,6 +27,29 @@ func TestGetServerIdFromCloneURL(t *testing.T) {
 	}
 }
 
+func TestParseRepositoryReference(t *testing.T) {
+	testCases := []struct {
+		input        string
+		expectedId   int64
+		expectedPath string
+	}{
+		{"123456", 123456, "123456"},
+		{"username/repo", 0, "username/repo"},
+		{"group/subgroup/repo", 0, "group/subgroup/repo"},
+		{"https://github.com/username/repo.git", 0, "username/repo"},
+		{"https://gitlab.com/group/subgroup/repo", 0, "group/subgroup/repo"},
+		{"git@github.com:username/repo.git", 0, "username/repo"},
+		{"ssh://git@gitlab.com:2222/group/repo.git", 0, "group/repo"},
+	}
+
+	for _, tc := range testCases {
+		id, path := ParseRepositoryReference(tc.input)
+		if id != tc.expectedId || path != tc.expectedPath {
+			t.Errorf("For input %s, expected (%d, %s), but got (%d, %s)", tc.input, tc.expectedId, tc.expectedPath, id, path)
+		}
+	}
+}
+
 func TestSlugify(t *testing.T) {
 	testCases := []struct {
 		input    string
@@ -45,3 +70,141 @@ func TestSlugify(t *testing.T) {
 		}
 	}
 }
+
+func TestIsMergeRequestUpToDate(t *testing.T) {
+	mr := MergeRequest{
+		Title:               "chore: update",
+		Description:         "description",
+		Labels:              []string{"b", "a"},
+		TargetBranch:        "main",
+		Assignees:           []User{{Username: "bot"}},
+		Reviewers:           []User{{Username: "reviewer"}, {Username: "org/team"}},
+		Squash:              ptr.True(),
+		RemoveSourceBranch:  ptr.False(),
+		AllowMaintainerEdit: ptr.True(),
+	}
+
+	testCases := []struct {
+		name     string
+		title    string
+		options  MergeRequestOptions
+		expected bool
+	}{
+		{"identical", "chore: update", MergeRequestOptions{}, true},
+		{"labels in different order", "chore: update", MergeRequestOptions{Labels: []string{"a", "b"}}, true},
+		{"title changed", "chore: other", MergeRequestOptions{}, false},
+		{"labels changed", "chore: update", MergeRequestOptions{Labels: []string{"a"}}, false},
+		{"draft changed", "chore: update", MergeRequestOptions{IsDraft: true}, false},
+		{"target branch changed", "chore: update", MergeRequestOptions{TargetBranch: "develop"}, false},
+		{"assignees changed", "chore: update", MergeRequestOptions{Assignees: []string{"user"}}, false},
+		{"auto-merge not enabled", "chore: update", MergeRequestOptions{AutoMerge: &MergeStrategyOptions{}}, false},
+		{"reviewers requested", "chore: update", MergeRequestOptions{Reviewers: []string{"org/team", "reviewer"}}, true},
+		{"reviewers subset", "chore: update", MergeRequestOptions{Reviewers: []string{"reviewer"}}, true},
+		{"reviewer added", "chore: update", MergeRequestOptions{Reviewers: []string{"reviewer", "other"}}, false},
+		{"squash unchanged", "chore: update", MergeRequestOptions{Squash: ptr.True()}, true},
+		{"squash changed", "chore: update", MergeRequestOptions{Squash: ptr.False()}, false},
+		{"remove source branch unchanged", "chore: update", MergeRequestOptions{RemoveSourceBranch: ptr.False()}, true},
+		{"remove source branch changed", "chore: update", MergeRequestOptions{RemoveSourceBranch: ptr.True()}, false},
+		{"allow maintainer edit unchanged", "chore: update", MergeRequestOptions{AllowMaintainerEdit: ptr.True()}, true},
+		{"allow maintainer edit changed", "chore: update", MergeRequestOptions{AllowMaintainerEdit: ptr.False()}, false},
+	}
+
+	for _, tc := range testCases {
+		result := IsMergeRequestUpToDate(mr, tc.title, "description", tc.options)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %t, but got %t", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestMergeRequestKey(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected string
+	}{
+		{"description\n\n" + MergeRequestKeyMarker("my-task"), "my-task"},
+		{"description\n\n<!--vcs-merge-request-key:dep/github.com/cidverse/go-vcs-->", "dep/github.com/cidverse/go-vcs"},
+		{"description without key", ""},
+	}
+
+	for _, tc := range testCases {
+		result := MergeRequestKey(tc.input)
+		if result != tc.expected {
+			t.Errorf("For input %s, expected %s, but got %s", tc.input, tc.expected, result)
+		}
+	}
+}
+
+func TestFindStickyComment(t *testing.T) {
+	comments := []MergeRequestComment{
+		{Id: 1, Body: "unrelated comment"},
+		{Id: 2, Body: "lint report\n" + StickyCommentMarker("lint-report")},
+		{Id: 3, Body: "coverage report\n" + StickyCommentMarker("coverage")},
+	}
+
+	testCases := []struct {
+		input    string
+		expected int64
+	}{
+		{"lint-report", 2},
+		{"coverage", 3},
+		{"lint", 0},
+	}
+
+	for _, tc := range testCases {
+		var result int64
+		if comment := FindStickyComment(comments, tc.input); comment != nil {
+			result = comment.Id
+		}
+		if result != tc.expected {
+			t.Errorf("For input %s, expected %d, but got %d", tc.input, tc.expected, result)
+		}
+	}
+}
+
+func TestAggregatePipelineState(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    []PipelineState
+		expected PipelineState
+	}{
+		{"no checks", nil, PipelineStateUnknown},
+		{"all successful", []PipelineState{PipelineStateSuccess, PipelineStateSkipped}, PipelineStateSuccess},
+		{"all skipped", []PipelineState{PipelineStateSkipped}, PipelineStateSkipped},
+		{"one failed", []PipelineState{PipelineStateSuccess, PipelineStateRunning, PipelineStateFailed}, PipelineStateFailed},
+		{"one running", []PipelineState{PipelineStateSuccess, PipelineStatePending, PipelineStateRunning}, PipelineStateRunning},
+		{"one queued", []PipelineState{PipelineStateSuccess, PipelineStatePending}, PipelineStatePending},
+	}
+
+	for _, tc := range testCases {
+		var checks []MergeRequestCheck
+		for _, state := range tc.input {
+			checks = append(checks, MergeRequestCheck{State: state})
+		}
+
+		result := AggregatePipelineState(checks)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %s, but got %s", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestResolveMergeMethod(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    MergeStrategyOptions
+		expected MergeMethod
+	}{
+		{"default", MergeStrategyOptions{}, ""},
+		{"explicit method", MergeStrategyOptions{Method: MergeMethodRebase}, MergeMethodRebase},
+		{"squash option", MergeStrategyOptions{Squash: ptr.True()}, MergeMethodSquash},
+		{"method takes precedence over squash", MergeStrategyOptions{Method: MergeMethodMerge, Squash: ptr.True()}, MergeMethodMerge},
+	}
+
+	for _, tc := range testCases {
+		result := ResolveMergeMethod(tc.input)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %s, but got %s", tc.name, tc.expected, result)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package githubapp
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bradleyfalzon/ghinstallation/v2"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/cidverse/go-vcsapp/pkg/platform/githubcommon"
+	"github.com/google/go-github/v88/github"
+)
+
+func convertRepository(repo *github.Repository, installation *github.Installation, itr *ghinstallation.Transport, client *github.Client) api.Repository {
+	r := api.Repository{
+		PlatformId:        api.GetServerIdFromCloneURL(repo.GetCloneURL()),
+		PlatformType:      "github",
+		Id:                repo.GetID(),
+		Namespace:         repo.GetOwner().GetLogin(),
+		Name:              repo.GetName(),
+		Path:              repo.GetFullName(),
+		Description:       repo.GetDescription(),
+		Type:              "git",
+		URL:               strings.TrimPrefix(repo.GetHTMLURL(), "https://"),
+		CloneURL:          repo.GetCloneURL(),
+		CloneSSH:          repo.GetSSHURL(),
+		DefaultBranch:     repo.GetDefaultBranch(),
+		IsFork:            repo.GetFork(),
+		IsEmpty:           false,
+		IsPersonalProject: strings.EqualFold(installation.GetAccount().GetType(), "user"),
+		Topics:            repo.Topics,
+		CreatedAt:         repo.CreatedAt.GetTime(),
+		Visibility:        githubcommon.ToRepositoryVisibility(repo),
+		IsArchived:        repo.GetArchived(),
+		Language:          repo.GetLanguage(),
+		StarCount:         repo.GetStargazersCount(),
+		ForkCount:         repo.GetForksCount(),
+		Size:              int64(repo.GetSize()),
+		LastActivityAt:    repo.PushedAt.GetTime(),
+		WebURL:            repo.GetHTMLURL(),
+		RoundTripper:      itr,
+		InternalClient:    client,
+		InternalRepo:      repo,
+	}
+	if repo.GetLicense() != nil {
+		r.LicenseName = repo.GetLicense().GetName()
+		r.LicenseURL = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/LICENSE", repo.GetOwner().GetLogin(), repo.GetName(), repo.GetDefaultBranch())
+	}
+
+	return r
+}
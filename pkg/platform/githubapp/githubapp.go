@@ -4,8 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 
@@ -13,7 +11,6 @@ import (
 	"github.com/cidverse/go-ptr"
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/cidverse/go-vcsapp/pkg/platform/githubcommon"
-	"github.com/go-git/go-git/v5"
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/google/go-github/v88/github"
 	"github.com/rs/zerolog/log"
@@ -47,6 +44,20 @@ func githubClientFromRepository(repo api.Repository) (*github.Client, error) {
 	return client, nil
 }
 
+// installationClient creates an installation-scoped transport and client
+func (n Platform) installationClient(installationId int64) (*ghinstallation.Transport, *github.Client, error) {
+	itr, err := ghinstallation.New(sharedTransport, n.appId, installationId, []byte(n.privateKey))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create installation transport: %w", err)
+	}
+	client, err := github.NewClient(github.WithTransport(itr))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create github client: %w", err)
+	}
+
+	return itr, client, nil
+}
+
 func (n Platform) Name() string {
 	return "GitHub"
 }
@@ -75,13 +86,9 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 	log.Info().Int("count", len(installations)).Msg("github platform - found app installations")
 
 	for _, installation := range installations {
-		itr, err := ghinstallation.New(sharedTransport, n.appId, *installation.ID, []byte(n.privateKey))
-		if err != nil {
-			return result, fmt.Errorf("failed to create installation transport: %w", err)
-		}
-		orgClient, err := github.NewClient(github.WithTransport(itr))
+		itr, orgClient, err := n.installationClient(installation.GetID())
 		if err != nil {
-			return result, fmt.Errorf("failed to create github client: %w", err)
+			return result, err
 		}
 
 		// query repositories
@@ -101,32 +108,7 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 		log.Debug().Str("org", installation.Account.GetLogin()).Int("count", len(repositories)).Msg("github platform - found repositories in organization")
 
 		for _, repo := range repositories {
-			r := api.Repository{
-				PlatformId:        api.GetServerIdFromCloneURL(repo.GetCloneURL()),
-				PlatformType:      "github",
-				Id:                repo.GetID(),
-				Namespace:         repo.GetOwner().GetLogin(),
-				Name:              repo.GetName(),
-				Path:              repo.GetFullName(),
-				Description:       repo.GetDescription(),
-				Type:              "git",
-				URL:               strings.TrimPrefix(repo.GetHTMLURL(), "https://"),
-				CloneURL:          repo.GetCloneURL(),
-				CloneSSH:          repo.GetSSHURL(),
-				DefaultBranch:     repo.GetDefaultBranch(),
-				IsFork:            repo.GetFork(),
-				IsEmpty:           false,
-				IsPersonalProject: strings.EqualFold(installation.GetAccount().GetType(), "user"),
-				Topics:            repo.Topics,
-				CreatedAt:         repo.CreatedAt.GetTime(),
-				RoundTripper:      itr,
-				InternalClient:    orgClient,
-				InternalRepo:      repo,
-			}
-			if repo.GetLicense() != nil {
-				r.LicenseName = repo.GetLicense().GetName()
-				r.LicenseURL = fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/LICENSE", repo.GetOwner().GetLogin(), repo.GetName(), repo.GetDefaultBranch())
-			}
+			r := convertRepository(repo, installation, itr, orgClient)
 
 			// commit
 			if opts.IncludeCommitHash {
@@ -148,10 +130,10 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 
 			// branches
 			if opts.IncludeBranches {
-				branchList, _, err := orgClient.Repositories.ListBranches(context.Background(), repo.GetOwner().GetLogin(), repo.GetName(), &github.BranchListOptions{})
+				branchList, err := githubcommon.ListBranches(r, orgClient)
 				if err != nil {
 					if !strings.Contains(err.Error(), "409 Git Repository is empty") {
-						return result, fmt.Errorf("failed to list branches: %w", err)
+						return result, err
 					} else {
 						r.IsEmpty = true
 					}
@@ -160,6 +142,15 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 				}
 			}
 
+			// merge requests
+			if opts.IncludeMergeRequestCount {
+				count, err := githubcommon.OpenMergeRequestCount(r, orgClient)
+				if err != nil {
+					return result, err
+				}
+				r.OpenMergeRequestCount = count
+			}
+
 			result = append(result, r)
 		}
 	}
@@ -168,7 +159,51 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 }
 
 func (n Platform) FindRepository(path string) (api.Repository, error) {
-	return api.Repository{}, fmt.Errorf("not implemented")
+	id, repoPath := api.ParseRepositoryReference(path)
+
+	// resolve the installation that has access to the repository
+	var installation *github.Installation
+	var err error
+	var owner, name string
+	if id != 0 {
+		installation, _, err = n.client.Apps.GetRepositoryInstallationByID(context.Background(), id)
+	} else {
+		owner, name, err = githubcommon.SplitRepositoryPath(repoPath)
+		if err != nil {
+			return api.Repository{}, err
+		}
+		installation, _, err = n.client.Apps.GetRepositoryInstallation(context.Background(), owner, name)
+	}
+	if err != nil {
+		return api.Repository{}, fmt.Errorf("failed to get installation for repository %s: %w", path, err)
+	}
+
+	itr, orgClient, err := n.installationClient(installation.GetID())
+	if err != nil {
+		return api.Repository{}, err
+	}
+
+	// find repository
+	var repo *github.Repository
+	if id != 0 {
+		repo, _, err = orgClient.Repositories.GetByID(context.Background(), id)
+	} else {
+		repo, _, err = orgClient.Repositories.Get(context.Background(), owner, name)
+	}
+	if err != nil {
+		return api.Repository{}, fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	return convertRepository(repo, installation, itr, orgClient), nil
+}
+
+func (n Platform) Branches(repo api.Repository) ([]api.Branch, error) {
+	client, err := githubClientFromRepository(repo)
+	if err != nil {
+		return nil, err
+	}
+
+	return githubcommon.Branches(repo, client)
 }
 
 func (n Platform) MergeRequests(repo api.Repository, options api.MergeRequestSearchOptions) ([]api.MergeRequest, error) {
@@ -218,56 +253,86 @@ func (n Platform) MergeRequests(repo api.Repository, options api.MergeRequestSea
 			continue
 		}
 
-		result = append(result, api.MergeRequest{
-			Id:           pr.GetID(),
-			Number:       pr.GetNumber(),
-			Title:        pr.GetTitle(),
-			Description:  pr.GetBody(),
-			Labels:       githubcommon.ToMergeRequestLabels(pr.Labels),
-			SourceBranch: pr.GetHead().GetRef(),
-			TargetBranch: pr.GetBase().GetRef(),
-			State:        githubcommon.ToStandardMergeRequestState(pr.GetState()),
-			IsMerged:     pr.GetMerged(),
-			IsLocked:     pr.GetLocked(),
-			IsDraft:      pr.GetDraft(),
-			HasConflicts: pr.GetMergeableState() == "dirty", // see https://docs.github.com/en/graphql/reference/enums#mergestatestatus
-			CanMerge:     pr.GetMergeable(),
-			Author:       githubcommon.ToStandardUser(pr.GetUser()),
-			Repository:   repo,
-		})
+		mr := githubcommon.ToMergeRequest(pr, repo)
+		if options.IncludePipelineState {
+			mr.PipelineState, mr.Checks, err = githubcommon.PipelineState(repo, client, pr.GetHead().GetSHA())
+			if err != nil {
+				return result, err
+			}
+		}
+
+		result = append(result, mr)
 	}
 
 	return result, nil
 }
 
+func (n Platform) GetMergeRequest(repo api.Repository, number int) (api.MergeRequest, error) {
+	client, err := githubClientFromRepository(repo)
+	if err != nil {
+		return api.MergeRequest{}, err
+	}
+
+	return githubcommon.GetMergeRequest(repo, client, number)
+}
+
+func (n Platform) FindMergeRequestByKey(repo api.Repository, key string, openOnly bool) (api.MergeRequest, error) {
+	client, err := githubClientFromRepository(repo)
+	if err != nil {
+		return api.MergeRequest{}, err
+	}
+
+	pr, err := githubcommon.FindMergeRequestByKey(repo, client, key, openOnly)
+	if err != nil {
+		return api.MergeRequest{}, err
+	}
+
+	return githubcommon.ToMergeRequest(pr, repo), nil
+}
+
 func (n Platform) MergeRequestDiff(repo api.Repository, mergeRequest api.MergeRequest) (api.MergeRequestDiff, error) {
-	result := api.MergeRequestDiff{
-		ChangedFiles: []api.MergeRequestFileDiff{},
+	client, err := githubClientFromRepository(repo)
+	if err != nil {
+		return api.MergeRequestDiff{}, err
 	}
+
+	return githubcommon.MergeRequestDiff(repo, client, mergeRequest.Number)
+}
+
+func (n Platform) MergeRequestComments(repo api.Repository, mergeRequest api.MergeRequest) ([]api.MergeRequestComment, error) {
 	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		return result, err
+		return nil, err
 	}
 
-	diff, _, err := client.PullRequests.ListFiles(context.Background(), repo.Namespace, repo.Name, int(mergeRequest.Id), &github.ListOptions{})
+	return githubcommon.MergeRequestComments(repo, client, mergeRequest.Number)
+}
+
+func (n Platform) CreateMergeRequestComment(repo api.Repository, mergeRequest api.MergeRequest, body string) (api.MergeRequestComment, error) {
+	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		return result, fmt.Errorf("failed to get diff: %w", err)
+		return api.MergeRequestComment{}, err
 	}
 
-	for _, d := range diff {
-		result.ChangedFiles = append(result.ChangedFiles, api.MergeRequestFileDiff{
-			IsNew:     d.GetStatus() == "added",
-			IsRenamed: d.GetStatus() == "renamed",
-			IsDeleted: d.GetStatus() == "removed",
-			OldPath:   d.GetPreviousFilename(),
-			NewPath:   d.GetFilename(),
-			OldMode:   "",
-			NewMode:   "",
-			Diff:      d.GetPatch(),
-		})
+	return githubcommon.CreateMergeRequestComment(repo, client, mergeRequest.Number, body)
+}
+
+func (n Platform) UpdateMergeRequestComment(repo api.Repository, mergeRequest api.MergeRequest, commentId int64, body string) (api.MergeRequestComment, error) {
+	client, err := githubClientFromRepository(repo)
+	if err != nil {
+		return api.MergeRequestComment{}, err
 	}
 
-	return result, nil
+	return githubcommon.UpdateMergeRequestComment(repo, client, commentId, body)
+}
+
+func (n Platform) DeleteMergeRequestComment(repo api.Repository, mergeRequest api.MergeRequest, commentId int64) error {
+	client, err := githubClientFromRepository(repo)
+	if err != nil {
+		return err
+	}
+
+	return githubcommon.DeleteMergeRequestComment(repo, client, commentId)
 }
 
 func (n Platform) SubmitReview(repo api.Repository, mergeRequest api.MergeRequest, approved bool, message *string) error {
@@ -277,7 +342,7 @@ func (n Platform) SubmitReview(repo api.Repository, mergeRequest api.MergeReques
 	}
 
 	if approved {
-		_, _, err := client.PullRequests.CreateReview(context.Background(), repo.Namespace, repo.Name, int(mergeRequest.Id), &github.PullRequestReviewRequest{
+		_, _, err := client.PullRequests.CreateReview(context.Background(), repo.Namespace, repo.Name, mergeRequest.Number, &github.PullRequestReviewRequest{
 			Event: ptr.Ptr("APPROVE"),
 			Body:  message,
 		})
@@ -285,7 +350,7 @@ func (n Platform) SubmitReview(repo api.Repository, mergeRequest api.MergeReques
 			return fmt.Errorf("failed to approve merge request: %w", err)
 		}
 	} else {
-		_, _, err := client.PullRequests.CreateReview(context.Background(), repo.Namespace, repo.Name, int(mergeRequest.Id), &github.PullRequestReviewRequest{
+		_, _, err := client.PullRequests.CreateReview(context.Background(), repo.Namespace, repo.Name, mergeRequest.Number, &github.PullRequestReviewRequest{
 			Event: ptr.Ptr("REQUEST_CHANGES"),
 			Body:  message,
 		})
@@ -297,236 +362,192 @@ func (n Platform) SubmitReview(repo api.Repository, mergeRequest api.MergeReques
 	return nil
 }
 
-func (n Platform) Merge(repo api.Repository, mergeRequest api.MergeRequest, mergeStrategy api.MergeStrategyOptions) error {
+func (n Platform) ApprovalState(repo api.Repository, mergeRequest api.MergeRequest) (api.ApprovalState, error) {
+	client, err := githubClientFromRepository(repo)
+	if err != nil {
+		return api.ApprovalState{}, err
+	}
+
+	return githubcommon.ApprovalState(repo, client, mergeRequest.Number)
+}
+
+func (n Platform) CreateReview(repo api.Repository, mergeRequest api.MergeRequest, review api.Review) error {
 	client, err := githubClientFromRepository(repo)
 	if err != nil {
 		return err
 	}
 
-	_, _, err = client.PullRequests.Merge(context.Background(), repo.Namespace, repo.Name, int(mergeRequest.Id), "", &github.PullRequestOptions{
-		MergeMethod: githubcommon.ToMergeMethod(mergeStrategy),
-	})
+	return githubcommon.CreateReview(repo, client, mergeRequest.Number, review)
+}
+
+func (n Platform) Merge(repo api.Repository, mergeRequest api.MergeRequest, mergeStrategy api.MergeStrategyOptions) error {
+	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		return fmt.Errorf("failed to merge merge request: %w", err)
+		return err
 	}
 
-	return nil
+	return githubcommon.Merge(repo, client, mergeRequest.Number, mergeStrategy)
 }
 
-func (n Platform) Languages(repo api.Repository) (map[string]int, error) {
+func (n Platform) EnableAutoMerge(repo api.Repository, mergeRequest api.MergeRequest, mergeStrategy api.MergeStrategyOptions) error {
 	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	data, _, err := client.Repositories.ListLanguages(context.Background(), repo.Namespace, repo.Name)
+	pr, _, err := client.PullRequests.Get(context.Background(), repo.Namespace, repo.Name, mergeRequest.Number)
 	if err != nil {
-		return nil, fmt.Errorf("failed to list languages: %w", err)
+		return fmt.Errorf("failed to get pull request: %w", err)
 	}
 
-	return data, err
+	return githubcommon.EnableAutoMerge(client, pr.GetNodeID(), mergeStrategy)
 }
 
-func (n Platform) AuthMethod(repo api.Repository) githttp.AuthMethod {
-	token, err := githubcommon.RoundTripperToAccessToken(repo.RoundTripper)
+func (n Platform) CloseMergeRequest(repo api.Repository, mergeRequest api.MergeRequest, message *string) error {
+	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to get access token")
+		return err
 	}
 
-	return &githttp.BasicAuth{
-		Username: strconv.FormatInt(n.appId, 10),
-		Password: token,
-	}
+	return githubcommon.CloseMergeRequest(repo, client, mergeRequest.Number, message)
 }
 
-func (n Platform) CommitAndPush(repo api.Repository, base string, branch string, message string, dir string) error {
+func (n Platform) ReopenMergeRequest(repo api.Repository, mergeRequest api.MergeRequest) error {
 	client, err := githubClientFromRepository(repo)
 	if err != nil {
 		return err
 	}
 
-	// prepare tree
-	var entries []*github.TreeEntry
+	return githubcommon.ReopenMergeRequest(repo, client, mergeRequest.Number)
+}
 
-	// get all changed files in directory
-	r, err := git.PlainOpen(dir)
+func (n Platform) DeleteBranch(repo api.Repository, branch string) error {
+	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		return fmt.Errorf("failed to open repository: %w", err)
+		return err
 	}
-	w, err := r.Worktree()
+
+	return githubcommon.DeleteBranch(repo, client, branch)
+}
+
+func (n Platform) Languages(repo api.Repository) (map[string]int, error) {
+	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		return fmt.Errorf("failed to get worktree: %w", err)
+		return nil, err
 	}
 
-	status, err := w.Status()
+	data, _, err := client.Repositories.ListLanguages(context.Background(), repo.Namespace, repo.Name)
 	if err != nil {
-		return fmt.Errorf("failed to get status: %w", err)
+		return nil, fmt.Errorf("failed to list languages: %w", err)
 	}
-	for file := range status {
-		filePath := filepath.Join(dir, file)
-
-		// deleted file
-		if _, err := os.Stat(filePath); os.IsNotExist(err) {
-			entries = append(entries, &github.TreeEntry{
-				Path: ptr.Ptr(file),
-				Type: ptr.Ptr("blob"),
-				Mode: ptr.Ptr("100644"),
-				SHA:  nil,
-			})
-			continue
-		}
 
-		// read file content
-		content, readErr := os.ReadFile(filePath)
-		if readErr != nil {
-			return fmt.Errorf("failed to read file: %w", readErr)
-		}
-		contentStr := api.UnifyLineEndings(string(content))
+	return data, err
+}
 
-		// get permissions
-		fileStats, statsErr := os.Stat(filePath)
-		if statsErr != nil {
-			return fmt.Errorf("failed to get file stats: %w", statsErr)
-		}
-		mode := "100644"
-		if fileStats.Mode()&os.FileMode(0111) != 0 {
-			mode = "100744" // executable files
-		}
-		entries = append(entries, &github.TreeEntry{
-			Path:    ptr.Ptr(file),
-			Type:    ptr.Ptr("blob"),
-			Content: ptr.Ptr(contentStr),
-			Mode:    ptr.Ptr(mode),
-		})
+func (n Platform) AuthMethod(repo api.Repository) githttp.AuthMethod {
+	token, err := githubcommon.RoundTripperToAccessToken(repo.RoundTripper)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to get access token")
 	}
 
-	// create tree
-	tree, _, err := client.Git.CreateTree(context.Background(), repo.Namespace, repo.Name, base, entries)
-	if err != nil {
-		return fmt.Errorf("failed to create tree: %w", err)
+	return &githttp.BasicAuth{
+		Username: strconv.FormatInt(n.appId, 10),
+		Password: token,
 	}
+}
+
+func (n Platform) CommitAndPush(repo api.Repository, base string, branch string, message string, dir string) error {
+	return n.PushCommits(repo, base, branch, []api.Commit{{Message: message}}, dir)
+}
 
-	// commit tree
-	commit, _, err := client.Git.CreateCommit(context.Background(), repo.Namespace, repo.Name, github.Commit{
-		Message: ptr.Ptr(message),
-		Tree:    tree,
-		Parents: []*github.Commit{{SHA: github.Ptr(base)}},
-	}, &github.CreateCommitOptions{})
+func (n Platform) PushCommits(repo api.Repository, base string, branch string, commits []api.Commit, dir string) error {
+	client, err := githubClientFromRepository(repo)
 	if err != nil {
-		return fmt.Errorf("failed to create commit: %w", err)
+		return err
 	}
 
-	// create or update remote reference
-	_, _, getRefErr := client.Git.GetRef(context.Background(), repo.Namespace, repo.Name, "refs/heads/"+branch)
-	if getRefErr != nil {
-		_, _, createRefErr := client.Git.CreateRef(context.Background(), repo.Namespace, repo.Name, github.CreateRef{
-			Ref: "refs/heads/" + branch,
-			SHA: commit.GetSHA(),
-		})
-		if createRefErr != nil {
-			return fmt.Errorf("failed to create remote branch reference: %w", createRefErr)
+	// commits created with an installation token are authored (and signed) by the bot user of the app
+	if api.RequiresSignOff(commits) {
+		app, _, err := n.client.Apps.Get(context.Background(), "")
+		if err != nil {
+			return fmt.Errorf("failed to get app: %w", err)
 		}
-	} else {
-		_, _, refErr := client.Git.UpdateRef(context.Background(), repo.Namespace, repo.Name, "refs/heads/"+branch, github.UpdateRef{
-			SHA:   commit.GetSHA(),
-			Force: ptr.True(),
-		})
-		if refErr != nil {
-			return fmt.Errorf("failed to update reference: %w", refErr)
+		bot, _, err := client.Users.Get(context.Background(), app.GetSlug()+"[bot]")
+		if err != nil {
+			return fmt.Errorf("failed to get bot user of app %s: %w", app.GetSlug(), err)
 		}
+		commits = api.SignOffCommits(commits, githubcommon.NoReplyAuthor(bot))
 	}
 
-	return nil
+	return githubcommon.PushCommits(repo, client, base, branch, commits, dir, nil)
 }
 
-func (n Platform) CreateMergeRequest(repository api.Repository, sourceBranch string, title string, description string) error {
+func (n Platform) CreateMergeRequest(repository api.Repository, sourceBranch string, title string, description string, options api.MergeRequestOptions) (api.MergeRequest, error) {
 	client, err := githubClientFromRepository(repository)
 	if err != nil {
-		return err
+		return api.MergeRequest{}, err
 	}
 
-	_, _, err = client.PullRequests.Create(context.Background(), repository.Namespace, repository.Name, &github.NewPullRequest{
-		Base:  ptr.Ptr(repository.DefaultBranch),
-		Head:  ptr.Ptr(sourceBranch),
-		Title: ptr.Ptr(title),
-		Body:  ptr.Ptr(description),
-	})
+	pr, err := githubcommon.CreateMergeRequest(repository, client, sourceBranch, title, description, options)
 	if err != nil {
-		return fmt.Errorf("failed to create merge request: %w", err)
+		return api.MergeRequest{}, err
 	}
 
-	return nil
+	return githubcommon.ToMergeRequest(pr, repository), nil
 }
 
-func (n Platform) CreateOrUpdateMergeRequest(repository api.Repository, sourceBranch string, title string, description string, key string) error {
+func (n Platform) CreateOrUpdateMergeRequest(repository api.Repository, sourceBranch string, title string, description string, key string, options api.MergeRequestOptions) (api.MergeRequest, api.MergeRequestOutcome, error) {
 	client, err := githubClientFromRepository(repository)
 	if err != nil {
-		return err
+		return api.MergeRequest{}, "", err
 	}
-	description = fmt.Sprintf("%s\n\n<!--vcs-merge-request-key:%s-->", description, key)
 
-	// search merge request
-	prs, _, err := client.PullRequests.List(context.Background(), repository.Namespace, repository.Name, &github.PullRequestListOptions{
-		Head:  sourceBranch,
-		Base:  repository.DefaultBranch,
-		State: "open",
-	})
+	pr, outcome, err := githubcommon.CreateOrUpdateMergeRequest(repository, client, sourceBranch, title, description, key, options)
 	if err != nil {
-		return fmt.Errorf("failed to list pull requests: %w", err)
-	}
-	var existingPR *github.PullRequest
-	for _, pr := range prs {
-		if sourceBranch != "" && pr.GetHead().GetRef() != sourceBranch {
-			continue
+		// the pull request may have been created or updated before the error occurred
+		if pr != nil {
+			return githubcommon.ToMergeRequest(pr, repository), outcome, err
 		}
-		if repository.DefaultBranch != "" && pr.GetBase().GetRef() != repository.DefaultBranch {
-			continue
-		}
-
-		existingPR = pr
-		break
+		return api.MergeRequest{}, "", err
 	}
 
-	if existingPR != nil {
-		log.Debug().Int64("id", existingPR.GetID()).Int("number", existingPR.GetNumber()).Str("source-branch", sourceBranch).Str("target-branch", repository.DefaultBranch).Msg("found existing pull request, updating")
-		_, _, updateErr := client.PullRequests.Edit(context.Background(), repository.Namespace, repository.Name, existingPR.GetNumber(), &github.PullRequest{
-			Title: ptr.Ptr(title),
-			Body:  ptr.Ptr(description),
-		})
-		if updateErr != nil {
-			return fmt.Errorf("failed to update pull request: %w", updateErr)
-		}
-	} else {
-		log.Debug().Str("source_branch", sourceBranch).Str("target_branch", repository.DefaultBranch).Str("title", title).Msg("no existing pull request found, creating")
-		_, _, createErr := client.PullRequests.Create(context.Background(), repository.Namespace, repository.Name, &github.NewPullRequest{
-			Base:  ptr.Ptr(repository.DefaultBranch),
-			Head:  ptr.Ptr(sourceBranch),
-			Title: ptr.Ptr(title),
-			Body:  ptr.Ptr(description),
-		})
-		if createErr != nil {
-			return fmt.Errorf("failed to create merge request: %w", createErr)
-		}
+	return githubcommon.ToMergeRequest(pr, repository), outcome, nil
+}
+
+func (n Platform) ListFiles(repository api.Repository, ref string, path string, recursive bool) ([]api.TreeEntry, error) {
+	client, err := githubClientFromRepository(repository)
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	return githubcommon.ListFiles(repository, client, ref, path, recursive)
 }
 
 func (n Platform) FileContent(repository api.Repository, branch string, path string) (string, error) {
-	client, err := githubClientFromRepository(repository)
+	file, err := n.File(repository, branch, path)
 	if err != nil {
 		return "", err
 	}
 
-	// get file content
-	fileContent, _, _, err := client.Repositories.GetContents(context.Background(), repository.Namespace, repository.Name, path, &github.RepositoryContentGetOptions{
-		Ref: branch,
-	})
+	return string(file.Content), nil
+}
+
+func (n Platform) File(repository api.Repository, ref string, path string) (api.File, error) {
+	client, err := githubClientFromRepository(repository)
+	if err != nil {
+		return api.File{}, err
+	}
+
+	return githubcommon.File(repository, client, ref, path)
+}
+
+func (n Platform) FileExists(repository api.Repository, ref string, path string) (bool, error) {
+	client, err := githubClientFromRepository(repository)
 	if err != nil {
-		return "", fmt.Errorf("failed to get file content: %w", err)
+		return false, err
 	}
 
-	return fileContent.GetContent()
+	return githubcommon.FileExists(repository, client, ref, path)
 }
 
 func (n Platform) Tags(repository api.Repository, limit int) ([]api.Tag, error) {
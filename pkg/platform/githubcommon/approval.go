@@ -0,0 +1,105 @@
+package githubcommon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v88/github"
+	"github.com/rs/zerolog/log"
+)
+
+// ApprovalState returns the approval state of a pull request, requirements are collected from the branch protection and rulesets of the base branch
+func ApprovalState(repo api.Repository, githubClient *github.Client, number int) (api.ApprovalState, error) {
+	var result api.ApprovalState
+
+	pr, _, err := githubClient.PullRequests.Get(context.Background(), repo.Namespace, repo.Name, number)
+	if err != nil {
+		return result, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	result.RequestedReviewers = ToStandardUsers(pr.RequestedReviewers)
+
+	reviews, err := listReviews(repo, githubClient, number)
+	if err != nil {
+		return result, err
+	}
+	result.ApprovedBy = approvals(reviews)
+
+	// the review decision considers all requirements, including code owners
+	var decision struct {
+		Repository struct {
+			PullRequest struct {
+				ReviewDecision *string `json:"reviewDecision"`
+			} `json:"pullRequest"`
+		} `json:"repository"`
+	}
+	query := `query($owner: String!, $name: String!, $number: Int!) { repository(owner: $owner, name: $name) { pullRequest(number: $number) { reviewDecision } } }`
+	err = GraphQL(githubClient, query, map[string]any{"owner": repo.Namespace, "name": repo.Name, "number": number}, &decision)
+	if err != nil {
+		return result, fmt.Errorf("failed to query review decision: %w", err)
+	}
+	reviewDecision := decision.Repository.PullRequest.ReviewDecision
+
+	// classic branch protection, requires admin permissions
+	enforcement, _, err := githubClient.Repositories.GetPullRequestReviewEnforcement(context.Background(), repo.Namespace, repo.Name, pr.GetBase().GetRef())
+	if IsNotFound(err) || IsForbidden(err) {
+		log.Debug().Err(err).Str("branch", pr.GetBase().GetRef()).Msg("no access to branch protection, skipping")
+	} else if err != nil {
+		return result, fmt.Errorf("failed to get branch protection: %w", err)
+	} else {
+		result.Rules = append(result.Rules, reviewRules("branch protection", enforcement.RequiredApprovingReviewCount, enforcement.RequireCodeOwnerReviews)...)
+	}
+
+	// rulesets, readable with read permissions
+	branchRules, _, err := githubClient.Repositories.ListRulesForBranch(context.Background(), repo.Namespace, repo.Name, pr.GetBase().GetRef(), &github.ListOptions{PerPage: PageSize})
+	if IsNotFound(err) || IsForbidden(err) {
+		log.Debug().Err(err).Str("branch", pr.GetBase().GetRef()).Msg("no access to rulesets, skipping")
+	} else if err != nil {
+		return result, fmt.Errorf("failed to list rules for branch: %w", err)
+	} else if branchRules != nil {
+		for _, rule := range branchRules.PullRequest {
+			result.Rules = append(result.Rules, reviewRules(fmt.Sprintf("ruleset %d", rule.RulesetID), rule.Parameters.RequiredApprovingReviewCount, rule.Parameters.RequireCodeOwnerReview)...)
+		}
+	}
+
+	for i, rule := range result.Rules {
+		switch rule.Type {
+		case api.ApprovalRuleTypeCodeOwner:
+			result.RequiresCodeOwnerApproval = true
+			result.Rules[i].IsApproved = reviewDecision != nil && *reviewDecision == "APPROVED"
+		default:
+			result.Rules[i].IsApproved = len(result.ApprovedBy) >= rule.RequiredApprovals
+		}
+		result.RequiredApprovals = max(result.RequiredApprovals, rule.RequiredApprovals)
+	}
+	result.ApprovalsLeft = max(0, result.RequiredApprovals-len(result.ApprovedBy))
+
+	// no review decision means that no reviews are required
+	if reviewDecision == nil {
+		result.IsApproved = result.ApprovalsLeft == 0
+	} else {
+		result.IsApproved = *reviewDecision == "APPROVED"
+	}
+
+	return result, nil
+}
+
+func reviewRules(name string, requiredApprovals int, requireCodeOwner bool) []api.ApprovalRule {
+	var result []api.ApprovalRule
+	if requiredApprovals > 0 {
+		result = append(result, api.ApprovalRule{
+			Name:              name,
+			Type:              api.ApprovalRuleTypeRegular,
+			RequiredApprovals: requiredApprovals,
+		})
+	}
+	if requireCodeOwner {
+		result = append(result, api.ApprovalRule{
+			Name:              name + " (code owners)",
+			Type:              api.ApprovalRuleTypeCodeOwner,
+			RequiredApprovals: 1,
+		})
+	}
+
+	return result
+}
@@ -0,0 +1,56 @@
+package githubcommon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v88/github"
+)
+
+// PipelineState returns the combined state of all check runs and commit statuses of a commit, together with the state of each check
+func PipelineState(repo api.Repository, githubClient *github.Client, sha string) (api.PipelineState, []api.MergeRequestCheck, error) {
+	var checks []api.MergeRequestCheck
+
+	// check runs, e.g. GitHub Actions
+	opts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: PageSize}}
+	for {
+		data, resp, err := githubClient.Checks.ListCheckRunsForRef(context.Background(), repo.Namespace, repo.Name, sha, opts)
+		if err != nil {
+			return api.PipelineStateUnknown, checks, fmt.Errorf("failed to list check runs: %w", err)
+		}
+		for _, run := range data.CheckRuns {
+			checks = append(checks, api.MergeRequestCheck{
+				Name:  run.GetName(),
+				State: ToCheckRunState(run.GetStatus(), run.GetConclusion()),
+				URL:   run.GetHTMLURL(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// commit statuses, e.g. external ci systems
+	statusOpts := &github.ListOptions{PerPage: PageSize}
+	for {
+		data, resp, err := githubClient.Repositories.GetCombinedStatus(context.Background(), repo.Namespace, repo.Name, sha, statusOpts)
+		if err != nil {
+			return api.PipelineStateUnknown, checks, fmt.Errorf("failed to get commit status: %w", err)
+		}
+		for _, status := range data.Statuses {
+			checks = append(checks, api.MergeRequestCheck{
+				Name:  status.GetContext(),
+				State: ToCommitStatusState(status.GetState()),
+				URL:   status.GetTargetURL(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		statusOpts.Page = resp.NextPage
+	}
+
+	return api.AggregatePipelineState(checks), checks, nil
+}
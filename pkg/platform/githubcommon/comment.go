@@ -0,0 +1,69 @@
+package githubcommon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v88/github"
+)
+
+// MergeRequestComments returns all issue comments of a pull request
+func MergeRequestComments(repo api.Repository, githubClient *github.Client, number int) ([]api.MergeRequestComment, error) {
+	var result []api.MergeRequestComment
+
+	opts := &github.IssueListCommentsOptions{
+		ListOptions: github.ListOptions{PerPage: PageSize},
+	}
+	for {
+		data, resp, err := githubClient.Issues.ListComments(context.Background(), repo.Namespace, repo.Name, number, opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to list pull request comments: %w", err)
+		}
+
+		for _, comment := range data {
+			result = append(result, ToMergeRequestComment(comment))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+// CreateMergeRequestComment adds an issue comment to a pull request
+func CreateMergeRequestComment(repo api.Repository, githubClient *github.Client, number int, body string) (api.MergeRequestComment, error) {
+	comment, _, err := githubClient.Issues.CreateComment(context.Background(), repo.Namespace, repo.Name, number, &github.IssueComment{
+		Body: &body,
+	})
+	if err != nil {
+		return api.MergeRequestComment{}, fmt.Errorf("failed to create pull request comment: %w", err)
+	}
+
+	return ToMergeRequestComment(comment), nil
+}
+
+// UpdateMergeRequestComment updates the body of an issue comment
+func UpdateMergeRequestComment(repo api.Repository, githubClient *github.Client, commentId int64, body string) (api.MergeRequestComment, error) {
+	comment, _, err := githubClient.Issues.EditComment(context.Background(), repo.Namespace, repo.Name, commentId, &github.IssueComment{
+		Body: &body,
+	})
+	if err != nil {
+		return api.MergeRequestComment{}, fmt.Errorf("failed to update pull request comment: %w", err)
+	}
+
+	return ToMergeRequestComment(comment), nil
+}
+
+// DeleteMergeRequestComment deletes an issue comment
+func DeleteMergeRequestComment(repo api.Repository, githubClient *github.Client, commentId int64) error {
+	_, err := githubClient.Issues.DeleteComment(context.Background(), repo.Namespace, repo.Name, commentId)
+	if err != nil {
+		return fmt.Errorf("failed to delete pull request comment: %w", err)
+	}
+
+	return nil
+}
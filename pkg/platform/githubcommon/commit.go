@@ -0,0 +1,250 @@
+package githubcommon
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"unicode/utf8"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/google/go-github/v88/github"
+	"github.com/rs/zerolog/log"
+)
+
+// PushCommits creates a sequence of commits using the Git Data API and points the branch to the last commit
+//
+// The commits are attributed to the authenticated identity, unless an author is provided.
+func PushCommits(repo api.Repository, githubClient *github.Client, base string, branch string, commits []api.Commit, dir string, author *api.GitAuthor) error {
+	// get all changed files in directory
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	lineEndings, err := api.NewLineEndingPolicy(dir)
+	if err != nil {
+		return err
+	}
+	var changedFiles []string
+	for file := range status {
+		changedFiles = append(changedFiles, file)
+	}
+
+	parent := base
+	for i, files := range api.AssignCommitPaths(changedFiles, commits) {
+		if len(files) == 0 {
+			log.Debug().Str("message", commits[i].Message).Msg("no changes for commit, skipping")
+			continue
+		}
+
+		entries, err := treeEntries(repo, githubClient, dir, idx, lineEndings, files)
+		if err != nil {
+			return err
+		}
+
+		// create tree
+		tree, _, err := githubClient.Git.CreateTree(context.Background(), repo.Namespace, repo.Name, parent, entries)
+		if err != nil {
+			return fmt.Errorf("failed to create tree: %w", err)
+		}
+
+		// commit tree
+		data := github.Commit{
+			Message: ptr.Ptr(api.CommitMessage(commits[i])),
+			Tree:    tree,
+			Parents: []*github.Commit{{SHA: github.Ptr(parent)}},
+		}
+		if author != nil {
+			data.Author = &github.CommitAuthor{Name: ptr.Ptr(author.Name), Email: ptr.Ptr(author.Email)}
+		}
+		commit, _, err := githubClient.Git.CreateCommit(context.Background(), repo.Namespace, repo.Name, data, &github.CreateCommitOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to create commit: %w", err)
+		}
+		parent = commit.GetSHA()
+	}
+	if parent == base {
+		return fmt.Errorf("no changes to commit")
+	}
+
+	// create or update remote reference
+	_, _, getRefErr := githubClient.Git.GetRef(context.Background(), repo.Namespace, repo.Name, "refs/heads/"+branch)
+	if getRefErr != nil {
+		_, _, createRefErr := githubClient.Git.CreateRef(context.Background(), repo.Namespace, repo.Name, github.CreateRef{
+			Ref: "refs/heads/" + branch,
+			SHA: parent,
+		})
+		if createRefErr != nil {
+			return fmt.Errorf("failed to create remote branch reference: %w", createRefErr)
+		}
+	} else {
+		_, _, refErr := githubClient.Git.UpdateRef(context.Background(), repo.Namespace, repo.Name, "refs/heads/"+branch, github.UpdateRef{
+			SHA:   parent,
+			Force: ptr.True(),
+		})
+		if refErr != nil {
+			return fmt.Errorf("failed to update reference: %w", refErr)
+		}
+	}
+
+	return nil
+}
+
+// treeEntries creates the tree entries for the changed files, preserving file modes, symlinks and submodules
+//
+// Binary files are uploaded as blobs once all files have been read, so that local errors do not leave unused blobs behind.
+func treeEntries(repo api.Repository, githubClient *github.Client, dir string, idx *index.Index, lineEndings api.LineEndingPolicy, files []string) ([]*github.TreeEntry, error) {
+	var entries []*github.TreeEntry
+	blobs := make(map[*github.TreeEntry][]byte)
+
+	for _, file := range files {
+		filePath := filepath.Join(dir, file)
+
+		// deleted file, the sha and content must be nil to remove the path from the tree
+		info, err := os.Lstat(filePath)
+		if os.IsNotExist(err) {
+			mode := filemode.Regular
+			if entry, entryErr := idx.Entry(file); entryErr == nil {
+				mode = entry.Mode
+			}
+			entries = append(entries, &github.TreeEntry{
+				Path: ptr.Ptr(file),
+				Type: ptr.Ptr(treeEntryType(mode)),
+				Mode: ptr.Ptr(treeEntryMode(mode)),
+				SHA:  nil,
+			})
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get file stats: %w", err)
+		}
+
+		mode, err := filemode.NewFromOSFileMode(info.Mode())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file mode of %s: %w", file, err)
+		}
+		switch mode {
+		case filemode.Dir, filemode.Submodule:
+			// submodule, the entry points to the checked out commit
+			sub, err := git.PlainOpen(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to open submodule %s: %w", file, err)
+			}
+			head, err := sub.Head()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get head of submodule %s: %w", file, err)
+			}
+			entries = append(entries, &github.TreeEntry{
+				Path: ptr.Ptr(file),
+				Type: ptr.Ptr(treeEntryType(filemode.Submodule)),
+				Mode: ptr.Ptr(treeEntryMode(filemode.Submodule)),
+				SHA:  ptr.Ptr(head.Hash().String()),
+			})
+		case filemode.Symlink:
+			// symlink, the content is the link target
+			target, err := os.Readlink(filePath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read symlink: %w", err)
+			}
+			entries = append(entries, &github.TreeEntry{
+				Path:    ptr.Ptr(file),
+				Type:    ptr.Ptr(treeEntryType(mode)),
+				Content: ptr.Ptr(filepath.ToSlash(target)),
+				Mode:    ptr.Ptr(treeEntryMode(mode)),
+			})
+		default:
+			// read file content
+			content, readErr := os.ReadFile(filePath)
+			if readErr != nil {
+				return nil, fmt.Errorf("failed to read file: %w", readErr)
+			}
+			content = lineEndings.Normalize(file, content)
+
+			// binary files are uploaded as base64 encoded blobs, inline content must be valid utf-8 text
+			if api.IsBinary(content) || !utf8.Valid(content) {
+				entry := &github.TreeEntry{
+					Path: ptr.Ptr(file),
+					Type: ptr.Ptr(treeEntryType(mode)),
+					Mode: ptr.Ptr(treeEntryMode(mode)),
+				}
+				entries = append(entries, entry)
+				blobs[entry] = content
+				continue
+			}
+
+			entries = append(entries, &github.TreeEntry{
+				Path:    ptr.Ptr(file),
+				Type:    ptr.Ptr(treeEntryType(mode)),
+				Content: ptr.Ptr(string(content)),
+				Mode:    ptr.Ptr(treeEntryMode(mode)),
+			})
+		}
+	}
+
+	// upload binary files, identical content is only uploaded once
+	uploaded := make(map[plumbing.Hash]string)
+	for _, entry := range entries {
+		content, ok := blobs[entry]
+		if !ok {
+			continue
+		}
+
+		hash := plumbing.ComputeHash(plumbing.BlobObject, content)
+		if _, ok = uploaded[hash]; !ok {
+			blob, _, err := githubClient.Git.CreateBlob(context.Background(), repo.Namespace, repo.Name, github.Blob{
+				Content:  ptr.Ptr(base64.StdEncoding.EncodeToString(content)),
+				Encoding: ptr.Ptr("base64"),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create blob for %s: %w", entry.GetPath(), err)
+			}
+			uploaded[hash] = blob.GetSHA()
+		}
+		entry.SHA = ptr.Ptr(uploaded[hash])
+	}
+
+	return entries, nil
+}
+
+// treeEntryMode returns the mode of a tree entry, e.g. 100644, 100755, 120000 or 160000
+func treeEntryMode(mode filemode.FileMode) string {
+	return fmt.Sprintf("%06o", uint32(mode))
+}
+
+// treeEntryType returns the object type of a tree entry, submodules reference a commit
+func treeEntryType(mode filemode.FileMode) string {
+	if mode == filemode.Submodule {
+		return "commit"
+	}
+	return "blob"
+}
+
+// NoReplyAuthor returns the commit identity of a GitHub user or bot, using the noreply email address GitHub assigns to commits created via the API
+func NoReplyAuthor(user *github.User) api.GitAuthor {
+	name := user.GetName()
+	if name == "" {
+		name = user.GetLogin()
+	}
+
+	return api.GitAuthor{
+		Name:  name,
+		Email: fmt.Sprintf("%d+%s@users.noreply.github.com", user.GetID(), user.GetLogin()),
+	}
+}
@@ -0,0 +1,177 @@
+package githubcommon
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestTreeEntriesBinary(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	files := map[string][]byte{
+		"README.md":  []byte("# readme\n"),
+		"image.png":  {0x89, 'P', 'N', 'G', 0x00, 0x01},
+		"latin1.txt": {'c', 'a', 'f', 0xe9},
+		"copy.png":   {0x89, 'P', 'N', 'G', 0x00, 0x01},
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	lineEndings, err := api.NewLineEndingPolicy(dir)
+	if err != nil {
+		t.Fatalf("failed to read line ending policy: %v", err)
+	}
+
+	var uploaded []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/cidverse/go-vcsapp/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		var blob struct {
+			Content  string `json:"content"`
+			Encoding string `json:"encoding"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&blob)
+		if blob.Encoding != "base64" {
+			t.Errorf("expected base64 encoded blob, but got %s", blob.Encoding)
+		}
+		content, _ := base64.StdEncoding.DecodeString(blob.Content)
+		uploaded = append(uploaded, string(content))
+		_, _ = fmt.Fprintf(w, `{"sha":"blob%d"}`, len(uploaded))
+	})
+
+	entries, err := treeEntries(api.Repository{Namespace: "cidverse", Name: "go-vcsapp"}, newTestClient(t, mux), dir, &index.Index{}, lineEndings, []string{"README.md", "image.png", "latin1.txt", "copy.png"})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if len(uploaded) != 2 {
+		t.Errorf("expected 2 uploaded blobs, but got %d", len(uploaded))
+	}
+
+	expected := map[string]string{"README.md": "", "image.png": "blob1", "latin1.txt": "blob2", "copy.png": "blob1"}
+	for _, entry := range entries {
+		sha, ok := expected[entry.GetPath()]
+		if !ok || entry.GetSHA() != sha || entry.GetMode() != "100644" {
+			t.Errorf("For file %s, expected sha %q, but got %q", entry.GetPath(), sha, entry.GetSHA())
+		}
+		if (sha == "") != (entry.Content != nil) {
+			t.Errorf("For file %s, expected inline content only for text files, but got %v", entry.GetPath(), entry.Content)
+		}
+	}
+}
+
+func TestTreeEntriesBinaryError(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := git.PlainInit(dir, false); err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "image.png"), []byte{0x00, 0x01}, 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	lineEndings, _ := api.NewLineEndingPolicy(dir)
+
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/cidverse/go-vcsapp/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	// a directory that is not a repository fails before any blob is uploaded
+	if err := os.Mkdir(filepath.Join(dir, "not-a-submodule"), 0755); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	_, err := treeEntries(api.Repository{Namespace: "cidverse", Name: "go-vcsapp"}, newTestClient(t, mux), dir, &index.Index{}, lineEndings, []string{"image.png", "not-a-submodule"})
+	if err == nil || requests != 0 {
+		t.Errorf("expected local error without blob requests, but got %v after %d requests", err, requests)
+	}
+
+	_, err = treeEntries(api.Repository{Namespace: "cidverse", Name: "go-vcsapp"}, newTestClient(t, mux), dir, &index.Index{}, lineEndings, []string{"image.png"})
+	if err == nil || !strings.Contains(err.Error(), "image.png") {
+		t.Errorf("expected error naming image.png, but got %v", err)
+	}
+}
+
+func TestTreeEntriesModes(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	w, _ := r.Worktree()
+	_ = os.WriteFile(filepath.Join(dir, "run.sh"), []byte("#!/bin/sh\n"), 0644)
+	_ = os.WriteFile(filepath.Join(dir, "tool.sh"), []byte("#!/bin/sh\n"), 0755)
+	_, _ = w.Add(".")
+	if _, err = w.Commit("initial", &git.CommitOptions{Author: &object.Signature{Name: "vcs-app", Email: "vcs-app@localhost"}}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// executable, symlink, submodule and removed tracked file
+	_ = os.Chmod(filepath.Join(dir, "run.sh"), 0755)
+	_ = os.Symlink("run.sh", filepath.Join(dir, "link.sh"))
+	sub, err := git.PlainInit(filepath.Join(dir, "submodule"), false)
+	if err != nil {
+		t.Fatalf("failed to init submodule: %v", err)
+	}
+	subWorktree, _ := sub.Worktree()
+	subHead, err := subWorktree.Commit("initial", &git.CommitOptions{AllowEmptyCommits: true, Author: &object.Signature{Name: "vcs-app", Email: "vcs-app@localhost"}})
+	if err != nil {
+		t.Fatalf("failed to commit in submodule: %v", err)
+	}
+	_ = os.Remove(filepath.Join(dir, "tool.sh"))
+
+	idx, _ := r.Storer.Index()
+	lineEndings, _ := api.NewLineEndingPolicy(dir)
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /repos/cidverse/go-vcsapp/git/blobs", func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("expected no blob uploads, but got %s", r.URL.Path)
+	})
+
+	entries, err := treeEntries(api.Repository{Namespace: "cidverse", Name: "go-vcsapp"}, newTestClient(t, mux), dir, idx, lineEndings, []string{"run.sh", "link.sh", "submodule", "tool.sh"})
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+
+	testCases := []struct {
+		path    string
+		mode    string
+		typ     string
+		sha     *string
+		content *string
+	}{
+		{"run.sh", "100755", "blob", nil, ptr.Ptr("#!/bin/sh\n")},
+		{"link.sh", "120000", "blob", nil, ptr.Ptr("run.sh")},
+		{"submodule", "160000", "commit", ptr.Ptr(subHead.String()), nil},
+		{"tool.sh", "100755", "blob", nil, nil},
+	}
+	if len(entries) != len(testCases) {
+		t.Fatalf("expected %d entries, but got %d", len(testCases), len(entries))
+	}
+	for i, tc := range testCases {
+		entry := entries[i]
+		if entry.GetPath() != tc.path || entry.GetMode() != tc.mode || entry.GetType() != tc.typ || !reflect.DeepEqual(entry.SHA, tc.sha) || !reflect.DeepEqual(entry.Content, tc.content) {
+			t.Errorf("For file %s, expected mode %s, type %s, sha %v and content %v, but got %s %s %v %v", tc.path, tc.mode, tc.typ, ptr.ValueOrDefault(tc.sha, ""), ptr.ValueOrDefault(tc.content, ""), entry.GetMode(), entry.GetType(), entry.GetSHA(), entry.GetContent())
+		}
+	}
+
+	// deleted files must serialize with a null sha to be removed from the tree
+	data, _ := json.Marshal(entries[3])
+	if !strings.Contains(string(data), `"sha":null`) {
+		t.Errorf("expected null sha for deleted file, but got %s", data)
+	}
+}
@@ -0,0 +1,169 @@
+package githubcommon
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v88/github"
+)
+
+// MergeRequestDiff returns all changed files of a pull request, file modes are resolved from the base and head trees
+func MergeRequestDiff(repo api.Repository, githubClient *github.Client, number int) (api.MergeRequestDiff, error) {
+	result := api.MergeRequestDiff{
+		ChangedFiles: []api.MergeRequestFileDiff{},
+	}
+
+	pr, _, err := githubClient.PullRequests.Get(context.Background(), repo.Namespace, repo.Name, number)
+	if err != nil {
+		return result, fmt.Errorf("failed to get pull request: %w", err)
+	}
+
+	var files []*github.CommitFile
+	opts := &github.ListOptions{PerPage: PageSize}
+	for {
+		data, resp, err := githubClient.PullRequests.ListFiles(context.Background(), repo.Namespace, repo.Name, number, opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to get diff: %w", err)
+		}
+		files = append(files, data...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	result.IsTruncated = len(files) < pr.GetChangedFiles()
+
+	var oldPaths, newPaths []string
+	for _, d := range files {
+		newPaths = append(newPaths, d.GetFilename())
+		oldPaths = append(oldPaths, d.GetFilename())
+		if d.GetPreviousFilename() != "" {
+			oldPaths = append(oldPaths, d.GetPreviousFilename())
+		}
+	}
+	oldModes, err := treeModes(repo, githubClient, pr.GetBase().GetSHA(), oldPaths)
+	if err != nil {
+		return result, err
+	}
+	newModes, err := treeModes(repo, githubClient, pr.GetHead().GetSHA(), newPaths)
+	if err != nil {
+		return result, err
+	}
+
+	for _, d := range files {
+		oldPath := d.GetFilename()
+		if d.GetPreviousFilename() != "" {
+			oldPath = d.GetPreviousFilename()
+		}
+		// GitHub omits the patch for binary files and for diffs that are too large
+		hasChanges := d.GetAdditions()+d.GetDeletions() > 0
+		oldMode := oldModes[oldPath]
+		newMode := newModes[d.GetFilename()]
+
+		result.ChangedFiles = append(result.ChangedFiles, api.MergeRequestFileDiff{
+			IsNew:       d.GetStatus() == "added",
+			IsRenamed:   d.GetStatus() == "renamed",
+			IsDeleted:   d.GetStatus() == "removed",
+			OldPath:     oldPath,
+			NewPath:     d.GetFilename(),
+			OldMode:     oldMode,
+			NewMode:     newMode,
+			Diff:        d.GetPatch(),
+			IsBinary:    isBinaryFile(d, oldMode, newMode),
+			IsTruncated: d.Patch == nil && hasChanges,
+			Hunks:       api.ParseDiffHunks(d.GetPatch()),
+		})
+	}
+
+	return result, nil
+}
+
+// emptyBlobSHA is the hash of a blob without content
+const emptyBlobSHA = "e69de29bb2d1d6434b8b29ae775ad8c2e48c5391"
+
+// isBinaryFile guesses if a file is binary, GitHub does not flag binary files but omits their patch and line counts
+func isBinaryFile(d *github.CommitFile, oldMode string, newMode string) bool {
+	if d.Patch != nil || d.GetAdditions()+d.GetDeletions() > 0 {
+		return false
+	}
+
+	switch d.GetStatus() {
+	case "renamed", "copied", "changed", "unchanged":
+		// renames and mode changes without content changes
+		return false
+	case "modified":
+		if oldMode != newMode {
+			return false
+		}
+	}
+
+	// empty files have no patch either
+	return d.GetSHA() != emptyBlobSHA
+}
+
+// treeModesBatchSize is the number of directories queried in a single graphql request
+const treeModesBatchSize = 50
+
+// treeModes returns the file modes of the given paths in the tree of a commit, indexed by path
+//
+// Only the parent directories of the paths are queried, a recursive tree of a large repository would be truncated.
+func treeModes(repo api.Repository, githubClient *github.Client, sha string, paths []string) (map[string]string, error) {
+	result := make(map[string]string)
+	if sha == "" || len(paths) == 0 {
+		return result, nil
+	}
+
+	var dirs []string
+	for _, p := range paths {
+		dir := path.Dir(p)
+		if dir == "." {
+			dir = ""
+		}
+		if !slices.Contains(dirs, dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	for batch := range slices.Chunk(dirs, treeModesBatchSize) {
+		var params, fields strings.Builder
+		vars := map[string]any{"owner": repo.Namespace, "name": repo.Name}
+		for i, dir := range batch {
+			fmt.Fprintf(&params, ", $e%d: String!", i)
+			fmt.Fprintf(&fields, " d%d: object(expression: $e%d) { ... on Tree { entries { path mode type } } }", i, i)
+			vars[fmt.Sprintf("e%d", i)] = sha + ":" + dir
+		}
+		query := fmt.Sprintf(`query($owner: String!, $name: String!%s) { repository(owner: $owner, name: $name) {%s } }`, params.String(), fields.String())
+
+		var trees struct {
+			Repository map[string]*struct {
+				Entries []struct {
+					Path string `json:"path"`
+					Mode int    `json:"mode"`
+					Type string `json:"type"`
+				} `json:"entries"`
+			} `json:"repository"`
+		}
+		err := GraphQL(githubClient, query, vars, &trees)
+		if err != nil {
+			return result, fmt.Errorf("failed to query trees of %s: %w", sha, err)
+		}
+
+		for _, tree := range trees.Repository {
+			if tree == nil {
+				// directory does not exist in this commit
+				continue
+			}
+			for _, entry := range tree.Entries {
+				if entry.Type == "blob" || entry.Type == "commit" {
+					result[entry.Path] = fmt.Sprintf("%06o", entry.Mode)
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
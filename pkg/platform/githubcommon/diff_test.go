@@ -0,0 +1,93 @@
+package githubcommon
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v88/github"
+)
+
+func TestIsBinaryFile(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    *github.CommitFile
+		oldMode  string
+		newMode  string
+		expected bool
+	}{
+		{"text file", &github.CommitFile{Status: ptr.Ptr("modified"), Additions: ptr.Ptr(1), Patch: ptr.Ptr("@@ -1 +1 @@")}, "100644", "100644", false},
+		{"too large diff", &github.CommitFile{Status: ptr.Ptr("modified"), Additions: ptr.Ptr(50000)}, "100644", "100644", false},
+		{"binary file", &github.CommitFile{Status: ptr.Ptr("modified"), SHA: ptr.Ptr("a1")}, "100644", "100644", true},
+		{"new binary file", &github.CommitFile{Status: ptr.Ptr("added"), SHA: ptr.Ptr("a1")}, "", "100644", true},
+		{"new empty file", &github.CommitFile{Status: ptr.Ptr("added"), SHA: ptr.Ptr(emptyBlobSHA)}, "", "100644", false},
+		{"mode change", &github.CommitFile{Status: ptr.Ptr("modified"), SHA: ptr.Ptr("a1")}, "100644", "100755", false},
+		{"type change", &github.CommitFile{Status: ptr.Ptr("changed"), SHA: ptr.Ptr("a1")}, "100644", "100755", false},
+		{"pure rename", &github.CommitFile{Status: ptr.Ptr("renamed"), SHA: ptr.Ptr("a1")}, "100644", "100644", false},
+	}
+
+	for _, tc := range testCases {
+		result := isBinaryFile(tc.input, tc.oldMode, tc.newMode)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %t, but got %t", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestMergeRequestDiff(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/cidverse/go-vcsapp/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"number":1,"changed_files":2,"base":{"sha":"base"},"head":{"sha":"head"}}`)
+	})
+	mux.HandleFunc("GET /repos/cidverse/go-vcsapp/pulls/1/files", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `[{"filename":"run.sh","status":"modified","sha":"a1"},{"filename":"docs/empty.md","status":"added","sha":"`+emptyBlobSHA+`"}]`)
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]any `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		// answer every queried directory, the mode of run.sh differs between base and head
+		data := map[string]any{}
+		for key, value := range body.Variables {
+			expression, ok := value.(string)
+			if !strings.HasPrefix(key, "e") || !ok {
+				continue
+			}
+			sha, dir, _ := strings.Cut(expression, ":")
+			var entries []map[string]any
+			switch {
+			case dir == "":
+				entries = append(entries, map[string]any{"path": "run.sh", "mode": map[string]int{"base": 0100644, "head": 0100755}[sha], "type": "blob"})
+			case dir == "docs" && sha == "head":
+				entries = append(entries, map[string]any{"path": "docs/empty.md", "mode": 0100644, "type": "blob"})
+			default:
+				data["d"+key[1:]] = nil
+				continue
+			}
+			data["d"+key[1:]] = map[string]any{"entries": entries}
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"repository": data}})
+	})
+
+	result, err := MergeRequestDiff(api.Repository{Namespace: "cidverse", Name: "go-vcsapp"}, newTestClient(t, mux), 1)
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if len(result.ChangedFiles) != 2 || result.IsTruncated {
+		t.Fatalf("expected 2 changed files, but got %+v", result)
+	}
+	script := result.ChangedFiles[0]
+	if script.OldMode != "100644" || script.NewMode != "100755" || script.IsBinary {
+		t.Errorf("expected mode-only change of run.sh, but got %+v", script)
+	}
+	empty := result.ChangedFiles[1]
+	if empty.OldMode != "" || empty.NewMode != "100644" || !empty.IsNew || empty.IsBinary {
+		t.Errorf("expected new empty file docs/empty.md, but got %+v", empty)
+	}
+}
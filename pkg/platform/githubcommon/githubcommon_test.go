@@ -0,0 +1,22 @@
+package githubcommon
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/google/go-github/v88/github"
+)
+
+// newTestClient returns a client that sends all requests to the handler
+func newTestClient(t *testing.T, handler http.Handler) *github.Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client, err := github.NewClient(github.WithURLs(ptr.Ptr(server.URL+"/"), nil), github.WithDisableRateLimitCheck())
+	if err != nil {
+		t.Fatalf("failed to create client: %v", err)
+	}
+	return client
+}
@@ -0,0 +1,51 @@
+package githubcommon
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v88/github"
+)
+
+type graphqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// GraphQL executes a GraphQL query or mutation, for features that are not available in the REST API
+func GraphQL(githubClient *github.Client, query string, variables map[string]any, result any) error {
+	// GitHub Enterprise Server serves GraphQL at /api/graphql instead of /api/v3/graphql
+	endpoint := "graphql"
+	if baseURL := githubClient.BaseURL(); strings.HasSuffix(baseURL, "/api/v3/") {
+		endpoint = strings.TrimSuffix(baseURL, "v3/") + "graphql"
+	}
+
+	req, err := githubClient.NewRequest(context.Background(), "POST", endpoint, map[string]any{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create graphql request: %w", err)
+	}
+
+	var resp graphqlResponse
+	_, err = githubClient.Do(req, &resp)
+	if err != nil {
+		return fmt.Errorf("failed to execute graphql request: %w", err)
+	}
+	if len(resp.Errors) > 0 {
+		return fmt.Errorf("graphql request failed: %s", resp.Errors[0].Message)
+	}
+
+	if result != nil {
+		if err = json.Unmarshal(resp.Data, result); err != nil {
+			return fmt.Errorf("failed to decode graphql response: %w", err)
+		}
+	}
+
+	return nil
+}
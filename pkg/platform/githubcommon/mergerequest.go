@@ -0,0 +1,589 @@
+package githubcommon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v88/github"
+	"github.com/rs/zerolog/log"
+)
+
+// TargetBranch returns the target branch of the merge request options, defaulting to the default branch of the repository
+func TargetBranch(repo api.Repository, options api.MergeRequestOptions) string {
+	if options.TargetBranch != "" {
+		return options.TargetBranch
+	}
+
+	return repo.DefaultBranch
+}
+
+// CreateMergeRequest creates a pull request and applies all merge request options
+func CreateMergeRequest(repo api.Repository, githubClient *github.Client, sourceBranch string, title string, description string, options api.MergeRequestOptions) (*github.PullRequest, error) {
+	pr, _, err := githubClient.PullRequests.Create(context.Background(), repo.Namespace, repo.Name, &github.NewPullRequest{
+		Base:                ptr.Ptr(TargetBranch(repo, options)),
+		Head:                ptr.Ptr(sourceBranch),
+		Title:               ptr.Ptr(title),
+		Body:                ptr.Ptr(description),
+		Draft:               ptr.Ptr(options.IsDraft),
+		MaintainerCanModify: options.AllowMaintainerEdit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+
+	err = applyMergeRequestOptions(repo, githubClient, pr, options)
+	if err != nil {
+		return pr, err
+	}
+
+	err = applyAutoMerge(repo, githubClient, pr, options)
+	if err != nil {
+		return pr, err
+	}
+
+	return pr, nil
+}
+
+// UpdateMergeRequest updates the title, description and options of an existing pull request
+func UpdateMergeRequest(repo api.Repository, githubClient *github.Client, existingPR *github.PullRequest, title string, description string, options api.MergeRequestOptions) (*github.PullRequest, error) {
+	pr, _, err := githubClient.PullRequests.Edit(context.Background(), repo.Namespace, repo.Name, existingPR.GetNumber(), &github.PullRequest{
+		Title:               ptr.Ptr(title),
+		Body:                ptr.Ptr(description),
+		Base:                &github.PullRequestBranch{Ref: ptr.Ptr(TargetBranch(repo, options))},
+		MaintainerCanModify: options.AllowMaintainerEdit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update pull request: %w", err)
+	}
+
+	// the draft state can only be changed using the GraphQL API
+	if pr.GetDraft() != options.IsDraft {
+		err = SetDraft(githubClient, pr.GetNodeID(), options.IsDraft)
+		if err != nil {
+			return pr, err
+		}
+		pr.Draft = ptr.Ptr(options.IsDraft)
+	}
+
+	err = applyMergeRequestOptions(repo, githubClient, pr, options)
+	if err != nil {
+		return pr, err
+	}
+
+	err = applyAutoMerge(repo, githubClient, pr, options)
+	if err != nil {
+		return pr, err
+	}
+
+	return pr, nil
+}
+
+// GetMergeRequest returns a pull request including commits, approvals and the pipeline state
+func GetMergeRequest(repo api.Repository, githubClient *github.Client, number int) (api.MergeRequest, error) {
+	pr, _, err := githubClient.PullRequests.Get(context.Background(), repo.Namespace, repo.Name, number)
+	if IsNotFound(err) {
+		return api.MergeRequest{}, fmt.Errorf("pull request #%d not found: %w", number, api.ErrNotFound)
+	} else if err != nil {
+		return api.MergeRequest{}, fmt.Errorf("failed to get pull request: %w", err)
+	}
+	result := ToMergeRequest(pr, repo)
+
+	// commits
+	opts := &github.ListOptions{PerPage: PageSize}
+	for {
+		data, resp, err := githubClient.PullRequests.ListCommits(context.Background(), repo.Namespace, repo.Name, number, opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to list pull request commits: %w", err)
+		}
+		for _, c := range data {
+			result.Commits = append(result.Commits, api.MergeRequestCommit{
+				Hash:    c.GetSHA(),
+				Message: c.GetCommit().GetMessage(),
+				Author: api.GitAuthor{
+					Name:  c.GetCommit().GetAuthor().GetName(),
+					Email: c.GetCommit().GetAuthor().GetEmail(),
+				},
+				AuthoredAt: c.GetCommit().GetAuthor().Date.GetTime(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// approvals, only the latest review of each user counts
+	reviews, err := listReviews(repo, githubClient, number)
+	if err != nil {
+		return result, err
+	}
+	result.Approvals = approvals(reviews)
+
+	result.PipelineState, result.Checks, err = PipelineState(repo, githubClient, pr.GetHead().GetSHA())
+	if err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// listReviews returns all reviews of a pull request
+func listReviews(repo api.Repository, githubClient *github.Client, number int) ([]*github.PullRequestReview, error) {
+	var result []*github.PullRequestReview
+
+	opts := &github.ListOptions{PerPage: PageSize}
+	for {
+		data, resp, err := githubClient.PullRequests.ListReviews(context.Background(), repo.Namespace, repo.Name, number, opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to list pull request reviews: %w", err)
+		}
+		result = append(result, data...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+// approvals returns the users whose latest review approved the pull request, comments do not revoke an approval
+func approvals(reviews []*github.PullRequestReview) []api.User {
+	latest := make(map[int64]*github.PullRequestReview)
+	var order []int64
+	for _, review := range reviews {
+		if review.GetState() == "COMMENTED" || review.GetState() == "PENDING" {
+			continue
+		}
+
+		id := review.GetUser().GetID()
+		if _, ok := latest[id]; !ok {
+			order = append(order, id)
+		}
+		latest[id] = review
+	}
+
+	var result []api.User
+	for _, id := range order {
+		if latest[id].GetState() == "APPROVED" {
+			result = append(result, ToStandardUser(latest[id].GetUser()))
+		}
+	}
+
+	return result
+}
+
+// FindMergeRequestByKey returns the pull request with the given vcs-merge-request-key, open pull requests take precedence over the most recently updated closed one
+func FindMergeRequestByKey(repo api.Repository, githubClient *github.Client, key string, openOnly bool) (*github.PullRequest, error) {
+	if key == "" {
+		return nil, fmt.Errorf("no pull request found for empty key: %w", api.ErrNotFound)
+	}
+
+	state := "all"
+	if openOnly {
+		state = "open"
+	}
+
+	var closedMatch *github.PullRequest
+	opts := github.ListOptions{PerPage: PageSize}
+	for {
+		data, resp, err := githubClient.PullRequests.List(context.Background(), repo.Namespace, repo.Name, &github.PullRequestListOptions{
+			State:       state,
+			Sort:        "updated",
+			Direction:   "desc",
+			ListOptions: opts,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pr := range data {
+			if api.MergeRequestKey(pr.GetBody()) != key {
+				continue
+			}
+			if pr.GetState() == "open" {
+				return pr, nil
+			}
+			if closedMatch == nil {
+				closedMatch = pr
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if closedMatch != nil {
+		return closedMatch, nil
+	}
+	return nil, fmt.Errorf("no pull request found for key %s: %w", key, api.ErrNotFound)
+}
+
+// CreateOrUpdateMergeRequest updates the open pull request with the same key or source branch, or creates a new one if none exists.
+// An open pull request with the same key but a different source branch (e.g. after changing the branch name template) is closed in favor of the new one.
+func CreateOrUpdateMergeRequest(repo api.Repository, githubClient *github.Client, sourceBranch string, title string, description string, key string, options api.MergeRequestOptions) (*github.PullRequest, api.MergeRequestOutcome, error) {
+	description = fmt.Sprintf("%s\n\n%s", description, api.MergeRequestKeyMarker(key))
+	targetBranch := TargetBranch(repo, options)
+
+	// search merge request by key
+	var existingPR *github.PullRequest
+	var supersededPR *github.PullRequest
+	keyedPR, err := FindMergeRequestByKey(repo, githubClient, key, true)
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		return nil, "", err
+	}
+	if keyedPR != nil && keyedPR.GetHead().GetRef() == sourceBranch {
+		existingPR = keyedPR
+	} else if keyedPR != nil {
+		supersededPR = keyedPR
+	}
+
+	// search merge request by source branch, for merge requests created without a key
+	if existingPR == nil {
+		prs, _, err := githubClient.PullRequests.List(context.Background(), repo.Namespace, repo.Name, &github.PullRequestListOptions{
+			Head:  repo.Namespace + ":" + sourceBranch,
+			Base:  targetBranch,
+			State: "open",
+		})
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to list pull requests: %w", err)
+		}
+		for _, pr := range prs {
+			if sourceBranch != "" && pr.GetHead().GetRef() != sourceBranch {
+				continue
+			}
+			if targetBranch != "" && pr.GetBase().GetRef() != targetBranch {
+				continue
+			}
+
+			existingPR = pr
+			break
+		}
+	}
+
+	var pr *github.PullRequest
+	var outcome api.MergeRequestOutcome
+	if existingPR != nil {
+		existingMR := ToMergeRequest(existingPR, repo)
+		if len(options.Reviewers) > 0 {
+			// review requests are removed once the reviewer submitted a review
+			reviews, err := listReviews(repo, githubClient, existingPR.GetNumber())
+			if err != nil {
+				return nil, "", err
+			}
+			for _, review := range reviews {
+				existingMR.Reviewers = append(existingMR.Reviewers, ToStandardUser(review.GetUser()))
+			}
+		}
+
+		// auto-merge is applied separately, it is rejected for pull requests that are already mergeable and would otherwise cause an update on every run
+		comparedOptions := options
+		comparedOptions.AutoMerge = nil
+		if api.IsMergeRequestUpToDate(existingMR, title, description, comparedOptions) {
+			log.Debug().Int64("id", existingPR.GetID()).Int("number", existingPR.GetNumber()).Msg("existing pull request is up to date")
+			pr, outcome = existingPR, api.MergeRequestOutcomeUnchanged
+			err = applyAutoMerge(repo, githubClient, pr, options)
+			if err != nil {
+				return pr, outcome, err
+			}
+		} else {
+			log.Debug().Int64("id", existingPR.GetID()).Int("number", existingPR.GetNumber()).Str("source-branch", sourceBranch).Str("target-branch", targetBranch).Msg("found existing pull request, updating")
+			outcome = api.MergeRequestOutcomeUpdated
+			pr, err = UpdateMergeRequest(repo, githubClient, existingPR, title, description, options)
+			if pr == nil {
+				return nil, "", err
+			} else if err != nil {
+				return pr, outcome, err
+			}
+		}
+	} else {
+		log.Debug().Str("source_branch", sourceBranch).Str("target_branch", targetBranch).Str("title", title).Msg("no existing pull request found, creating")
+		outcome = api.MergeRequestOutcomeCreated
+		pr, err = CreateMergeRequest(repo, githubClient, sourceBranch, title, description, options)
+		if pr == nil {
+			return nil, "", err
+		} else if err != nil {
+			return pr, outcome, err
+		}
+	}
+
+	// close the pull request that was replaced, also if the current one was found by source branch
+	if supersededPR != nil && supersededPR.GetNumber() != pr.GetNumber() {
+		log.Debug().Int("number", supersededPR.GetNumber()).Str("source-branch", supersededPR.GetHead().GetRef()).Int("superseded-by", pr.GetNumber()).Msg("closing pull request with the same key")
+		err = CloseMergeRequest(repo, githubClient, supersededPR.GetNumber(), ptr.Ptr(fmt.Sprintf("Superseded by #%d.", pr.GetNumber())))
+		if err != nil {
+			return pr, outcome, err
+		}
+	}
+
+	return pr, outcome, nil
+}
+
+// CloseMergeRequest closes a pull request, optionally adding a comment
+func CloseMergeRequest(repo api.Repository, githubClient *github.Client, number int, message *string) error {
+	if message != nil {
+		_, _, err := githubClient.Issues.CreateComment(context.Background(), repo.Namespace, repo.Name, number, &github.IssueComment{
+			Body: message,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to comment on pull request: %w", err)
+		}
+	}
+
+	_, _, err := githubClient.PullRequests.Edit(context.Background(), repo.Namespace, repo.Name, number, &github.PullRequest{
+		State: ptr.Ptr("closed"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to close pull request: %w", err)
+	}
+
+	return nil
+}
+
+// ReopenMergeRequest reopens a closed pull request
+func ReopenMergeRequest(repo api.Repository, githubClient *github.Client, number int) error {
+	_, _, err := githubClient.PullRequests.Edit(context.Background(), repo.Namespace, repo.Name, number, &github.PullRequest{
+		State: ptr.Ptr("open"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reopen pull request: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteBranch deletes a branch
+func DeleteBranch(repo api.Repository, githubClient *github.Client, branch string) error {
+	_, err := githubClient.Git.DeleteRef(context.Background(), repo.Namespace, repo.Name, "heads/"+branch)
+	if err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", branch, err)
+	}
+
+	return nil
+}
+
+// Merge merges a pull request, fails if the requested merge method is not allowed in the repository settings
+func Merge(repo api.Repository, githubClient *github.Client, number int, mergeStrategy api.MergeStrategyOptions) error {
+	method, err := ToMergeMethod(mergeStrategy)
+	if err != nil {
+		return err
+	}
+	if method != "" {
+		err = checkMergeMethodAllowed(repo, githubClient, method)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, _, err = githubClient.PullRequests.Merge(context.Background(), repo.Namespace, repo.Name, number, mergeStrategy.CommitMessage, &github.PullRequestOptions{
+		MergeMethod: method,
+		CommitTitle: mergeStrategy.CommitTitle,
+		SHA:         mergeStrategy.ExpectedHeadSHA,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to merge pull request: %w", err)
+	}
+
+	return nil
+}
+
+// checkMergeMethodAllowed returns an error if the merge method is disabled in the repository settings, the settings are only visible with push access
+func checkMergeMethodAllowed(repo api.Repository, githubClient *github.Client, method string) error {
+	r, _, err := githubClient.Repositories.Get(context.Background(), repo.Namespace, repo.Name)
+	if err != nil {
+		return fmt.Errorf("failed to get repository: %w", err)
+	}
+
+	allowed := map[string]*bool{
+		"merge":  r.AllowMergeCommit,
+		"squash": r.AllowSquashMerge,
+		"rebase": r.AllowRebaseMerge,
+	}[method]
+	if allowed != nil && !*allowed {
+		return fmt.Errorf("merge method %s is not allowed in repository %s", method, repo.Path)
+	}
+
+	return nil
+}
+
+// EnableAutoMerge enables auto-merge for a pull request, requires auto-merge to be allowed in the repository settings
+func EnableAutoMerge(githubClient *github.Client, pullRequestNodeId string, mergeStrategy api.MergeStrategyOptions) error {
+	method, err := ToAutoMergeMethod(mergeStrategy)
+	if err != nil {
+		return err
+	}
+
+	mutation := `mutation($id: ID!, $method: PullRequestMergeMethod!, $headline: String, $body: String, $oid: GitObjectID) {
+		enablePullRequestAutoMerge(input: {pullRequestId: $id, mergeMethod: $method, commitHeadline: $headline, commitBody: $body, expectedHeadOid: $oid}) { clientMutationId }
+	}`
+	variables := map[string]any{"id": pullRequestNodeId, "method": method, "headline": nil, "body": nil, "oid": nil}
+	if mergeStrategy.CommitTitle != "" {
+		variables["headline"] = mergeStrategy.CommitTitle
+	}
+	if mergeStrategy.CommitMessage != "" {
+		variables["body"] = mergeStrategy.CommitMessage
+	}
+	if mergeStrategy.ExpectedHeadSHA != "" {
+		variables["oid"] = mergeStrategy.ExpectedHeadSHA
+	}
+
+	err = GraphQL(githubClient, mutation, variables, nil)
+	if err != nil {
+		return fmt.Errorf("failed to enable auto-merge for pull request: %w", err)
+	}
+
+	return nil
+}
+
+// applyAutoMerge enables auto-merge if requested in the options and not yet enabled, drafts can not be auto-merged.
+// GitHub rejects auto-merge for pull requests that are already mergeable (clean status), these are merged directly if MergeIfMergeable is set or left open otherwise.
+func applyAutoMerge(repo api.Repository, githubClient *github.Client, pr *github.PullRequest, options api.MergeRequestOptions) error {
+	if options.AutoMerge == nil || pr.GetDraft() || pr.AutoMerge != nil {
+		return nil
+	}
+
+	status, err := mergeStateStatus(githubClient, pr.GetNodeID())
+	if err != nil {
+		return err
+	}
+	if status == "CLEAN" {
+		if !options.AutoMerge.MergeIfMergeable {
+			log.Info().Int("number", pr.GetNumber()).Msg("pull request is already mergeable, auto-merge is not available")
+			return nil
+		}
+
+		log.Info().Int("number", pr.GetNumber()).Msg("pull request is already mergeable, merging directly")
+		err = Merge(repo, githubClient, pr.GetNumber(), *options.AutoMerge)
+		if err != nil {
+			return err
+		}
+		pr.Merged = ptr.True()
+		pr.State = ptr.Ptr("closed")
+		return nil
+	}
+
+	err = EnableAutoMerge(githubClient, pr.GetNodeID(), *options.AutoMerge)
+	if err != nil {
+		return err
+	}
+	pr.AutoMerge = &github.PullRequestAutoMerge{}
+
+	return nil
+}
+
+const (
+	mergeStateAttempts = 5
+	mergeStateInterval = 2 * time.Second
+)
+
+// mergeStateStatus returns the merge state status of a pull request (e.g. CLEAN, BLOCKED or BEHIND), GitHub computes it asynchronously and reports UNKNOWN until then
+func mergeStateStatus(githubClient *github.Client, pullRequestNodeId string) (string, error) {
+	var data struct {
+		Node struct {
+			MergeStateStatus string `json:"mergeStateStatus"`
+		} `json:"node"`
+	}
+	query := `query($id: ID!) { node(id: $id) { ... on PullRequest { mergeStateStatus } } }`
+	for attempt := 1; ; attempt++ {
+		err := GraphQL(githubClient, query, map[string]any{"id": pullRequestNodeId}, &data)
+		if err != nil {
+			return "", fmt.Errorf("failed to get merge state of pull request: %w", err)
+		}
+		if data.Node.MergeStateStatus != "UNKNOWN" || attempt == mergeStateAttempts {
+			return data.Node.MergeStateStatus, nil
+		}
+		time.Sleep(mergeStateInterval)
+	}
+}
+
+// SetDraft converts a pull request to a draft or marks it as ready for review
+func SetDraft(githubClient *github.Client, pullRequestNodeId string, draft bool) error {
+	mutation := `mutation($id: ID!) { markPullRequestReadyForReview(input: {pullRequestId: $id}) { clientMutationId } }`
+	if draft {
+		mutation = `mutation($id: ID!) { convertPullRequestToDraft(input: {pullRequestId: $id}) { clientMutationId } }`
+	}
+
+	err := GraphQL(githubClient, mutation, map[string]any{"id": pullRequestNodeId}, nil)
+	if err != nil {
+		return fmt.Errorf("failed to change draft state of pull request: %w", err)
+	}
+
+	return nil
+}
+
+// applyMergeRequestOptions sets labels, assignees, milestone and reviewers of a pull request and updates the pull request object accordingly
+func applyMergeRequestOptions(repo api.Repository, githubClient *github.Client, pr *github.PullRequest, options api.MergeRequestOptions) error {
+	issueRequest := &github.IssueRequest{}
+	changed := false
+	if options.Labels != nil {
+		issueRequest.Labels = ptr.Ptr(options.Labels)
+		changed = true
+	}
+	if options.Assignees != nil {
+		issueRequest.Assignees = ptr.Ptr(options.Assignees)
+		changed = true
+	}
+	if options.Milestone != "" {
+		milestone, err := findMilestone(repo, githubClient, options.Milestone)
+		if err != nil {
+			return err
+		}
+		issueRequest.Milestone = ptr.Ptr(milestone.GetNumber())
+		changed = true
+	}
+	if changed {
+		issue, _, err := githubClient.Issues.Edit(context.Background(), repo.Namespace, repo.Name, pr.GetNumber(), issueRequest)
+		if err != nil {
+			return fmt.Errorf("failed to update labels, assignees or milestone of pull request: %w", err)
+		}
+		pr.Labels = issue.Labels
+		pr.Assignees = issue.Assignees
+		pr.Milestone = issue.Milestone
+	}
+
+	if len(options.Reviewers) > 0 {
+		reviewers := github.ReviewersRequest{}
+		for _, reviewer := range options.Reviewers {
+			if _, team, isTeam := strings.Cut(reviewer, "/"); isTeam {
+				reviewers.TeamReviewers = append(reviewers.TeamReviewers, team)
+			} else {
+				reviewers.Reviewers = append(reviewers.Reviewers, reviewer)
+			}
+		}
+
+		updatedPR, _, err := githubClient.PullRequests.RequestReviewers(context.Background(), repo.Namespace, repo.Name, pr.GetNumber(), reviewers)
+		if err != nil {
+			return fmt.Errorf("failed to request reviewers: %w", err)
+		}
+		pr.RequestedReviewers = updatedPR.RequestedReviewers
+	}
+
+	return nil
+}
+
+// findMilestone returns the open milestone with the given title
+func findMilestone(repo api.Repository, githubClient *github.Client, title string) (*github.Milestone, error) {
+	opts := github.ListOptions{PerPage: PageSize}
+	for {
+		data, resp, err := githubClient.Issues.ListMilestones(context.Background(), repo.Namespace, repo.Name, &github.MilestoneListOptions{State: "open", ListOptions: opts})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list milestones: %w", err)
+		}
+		for _, m := range data {
+			if m.GetTitle() == title {
+				return m, nil
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return nil, fmt.Errorf("milestone %s not found in repository %s", title, repo.Path)
+}
@@ -0,0 +1,82 @@
+package githubcommon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+)
+
+func TestCreateOrUpdateMergeRequestAutoMergeState(t *testing.T) {
+	repo := api.Repository{Namespace: "cidverse", Name: "go-vcsapp", DefaultBranch: "main"}
+	existing := map[string]any{"number": 1, "node_id": "PR_1", "state": "open", "title": "chore: update", "body": "description\n\n" + api.MergeRequestKeyMarker("key"), "head": map[string]any{"ref": "chore/update"}, "base": map[string]any{"ref": "main"}}
+
+	testCases := []struct {
+		name             string
+		existing         bool
+		mergeState       string
+		mergeIfMergeable bool
+		expectedOutcome  api.MergeRequestOutcome
+		expectedMerged   bool
+		expectedAuto     bool
+	}{
+		{"created, already mergeable", false, "CLEAN", false, api.MergeRequestOutcomeCreated, false, false},
+		{"created, merged directly", false, "CLEAN", true, api.MergeRequestOutcomeCreated, true, false},
+		{"existing, already mergeable", true, "CLEAN", false, api.MergeRequestOutcomeUnchanged, false, false},
+		{"created, auto-merge enabled", false, "BLOCKED", true, api.MergeRequestOutcomeCreated, false, true},
+	}
+
+	for _, tc := range testCases {
+		var edited, merged, autoMerge bool
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /repos/cidverse/go-vcsapp/pulls", func(w http.ResponseWriter, r *http.Request) {
+			if tc.existing {
+				_ = json.NewEncoder(w).Encode([]any{existing})
+				return
+			}
+			_, _ = fmt.Fprint(w, `[]`)
+		})
+		mux.HandleFunc("POST /repos/cidverse/go-vcsapp/pulls", func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(existing)
+		})
+		mux.HandleFunc("PATCH /repos/cidverse/go-vcsapp/pulls/1", func(w http.ResponseWriter, r *http.Request) {
+			edited = true
+			_ = json.NewEncoder(w).Encode(existing)
+		})
+		mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if strings.Contains(string(body), "enablePullRequestAutoMerge") {
+				autoMerge = true
+				_, _ = fmt.Fprint(w, `{"data":{}}`)
+				return
+			}
+			_, _ = fmt.Fprintf(w, `{"data":{"node":{"mergeStateStatus":%q}}}`, tc.mergeState)
+		})
+		mux.HandleFunc("PUT /repos/cidverse/go-vcsapp/pulls/1/merge", func(w http.ResponseWriter, r *http.Request) {
+			merged = true
+			_, _ = fmt.Fprint(w, `{"merged":true}`)
+		})
+
+		options := api.MergeRequestOptions{AutoMerge: &api.MergeStrategyOptions{MergeIfMergeable: tc.mergeIfMergeable}}
+		pr, outcome, err := CreateOrUpdateMergeRequest(repo, newTestClient(t, mux), "chore/update", "chore: update", "description", "key", options)
+		if err != nil {
+			t.Fatalf("For case %s, expected no error, but got %v", tc.name, err)
+		}
+		if pr.GetNumber() != 1 || outcome != tc.expectedOutcome {
+			t.Errorf("For case %s, expected pull request #1 with outcome %s, but got #%d with %s", tc.name, tc.expectedOutcome, pr.GetNumber(), outcome)
+		}
+		if merged != tc.expectedMerged || pr.GetMerged() != tc.expectedMerged {
+			t.Errorf("For case %s, expected merged %t, but got %t", tc.name, tc.expectedMerged, merged)
+		}
+		if autoMerge != tc.expectedAuto {
+			t.Errorf("For case %s, expected auto-merge %t, but got %t", tc.name, tc.expectedAuto, autoMerge)
+		}
+		if edited {
+			t.Errorf("For case %s, expected no update of the pull request", tc.name)
+		}
+	}
+}
@@ -0,0 +1,66 @@
+package githubcommon
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/google/go-github/v88/github"
+)
+
+// CreateReview submits a pull request review with inline comments
+func CreateReview(repo api.Repository, githubClient *github.Client, number int, review api.Review) error {
+	event, err := toReviewEvent(review.Event)
+	if err != nil {
+		return err
+	}
+
+	request := &github.PullRequestReviewRequest{
+		Event: ptr.Ptr(event),
+	}
+	if review.Body != "" {
+		request.Body = ptr.Ptr(review.Body)
+	}
+	if review.CommitSHA != "" {
+		request.CommitID = ptr.Ptr(review.CommitSHA)
+	}
+	for _, c := range review.Comments {
+		side, line := "RIGHT", c.NewLine
+		if line == 0 {
+			side, line = "LEFT", c.OldLine
+		}
+
+		comment := &github.DraftReviewComment{
+			Path: ptr.Ptr(c.Path),
+			Body: ptr.Ptr(c.Body),
+			Line: ptr.Ptr(line),
+			Side: ptr.Ptr(side),
+		}
+		if c.StartLine > 0 && c.StartLine < line {
+			comment.StartLine = ptr.Ptr(c.StartLine)
+			comment.StartSide = ptr.Ptr(side)
+		}
+		request.Comments = append(request.Comments, comment)
+	}
+
+	_, _, err = githubClient.PullRequests.CreateReview(context.Background(), repo.Namespace, repo.Name, number, request)
+	if err != nil {
+		return fmt.Errorf("failed to create pull request review: %w", err)
+	}
+
+	return nil
+}
+
+func toReviewEvent(event api.ReviewEvent) (string, error) {
+	switch event {
+	case api.ReviewEventApprove:
+		return "APPROVE", nil
+	case api.ReviewEventRequestChanges:
+		return "REQUEST_CHANGES", nil
+	case api.ReviewEventComment, "":
+		return "COMMENT", nil
+	}
+
+	return "", fmt.Errorf("unsupported review event: %s", event)
+}
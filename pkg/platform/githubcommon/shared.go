@@ -3,6 +3,9 @@ package githubcommon
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"time"
 
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/google/go-github/v88/github"
@@ -122,3 +125,263 @@ func Environments(repo api.Repository, githubClient *github.Client) ([]api.CIEnv
 
 	return result, nil
 }
+
+// ListBranches returns all branches of a repository, following pagination
+func ListBranches(repo api.Repository, githubClient *github.Client) ([]*github.Branch, error) {
+	var branches []*github.Branch
+
+	opts := github.ListOptions{PerPage: PageSize}
+	for {
+		data, resp, err := githubClient.Repositories.ListBranches(context.Background(), repo.Namespace, repo.Name, &github.BranchListOptions{ListOptions: opts})
+		if err != nil {
+			return branches, fmt.Errorf("failed to list branches: %w", err)
+		}
+		branches = append(branches, data...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return branches, nil
+}
+
+// Branches returns all branches of a repository with their head commit and protection status
+func Branches(repo api.Repository, githubClient *github.Client) ([]api.Branch, error) {
+	var result []api.Branch
+
+	branches, err := ListBranches(repo, githubClient)
+	if err != nil {
+		return result, err
+	}
+
+	// the list endpoint only returns the commit sha, query the commit dates of all branches via graphql
+	commitDates, err := branchCommitDates(repo, githubClient)
+	if err != nil {
+		return result, err
+	}
+
+	for _, b := range branches {
+		result = append(result, api.Branch{
+			Name:        b.GetName(),
+			CommitHash:  b.GetCommit().GetSHA(),
+			CommitDate:  commitDates[b.GetName()],
+			IsDefault:   b.GetName() == repo.DefaultBranch,
+			IsProtected: b.GetProtected(),
+		})
+	}
+
+	return result, nil
+}
+
+// branchCommitDates returns the commit date of the head commit of each branch, indexed by branch name
+func branchCommitDates(repo api.Repository, githubClient *github.Client) (map[string]*time.Time, error) {
+	result := make(map[string]*time.Time)
+
+	var refs struct {
+		Repository struct {
+			Refs struct {
+				Nodes []struct {
+					Name   string `json:"name"`
+					Target struct {
+						CommittedDate *time.Time `json:"committedDate"`
+					} `json:"target"`
+				} `json:"nodes"`
+				PageInfo struct {
+					HasNextPage bool   `json:"hasNextPage"`
+					EndCursor   string `json:"endCursor"`
+				} `json:"pageInfo"`
+			} `json:"refs"`
+		} `json:"repository"`
+	}
+	query := `query($owner: String!, $name: String!, $cursor: String) { repository(owner: $owner, name: $name) { refs(refPrefix: "refs/heads/", first: 100, after: $cursor) { nodes { name target { ... on Commit { committedDate } } } pageInfo { hasNextPage endCursor } } } }`
+	vars := map[string]any{"owner": repo.Namespace, "name": repo.Name, "cursor": nil}
+	for {
+		err := GraphQL(githubClient, query, vars, &refs)
+		if err != nil {
+			return result, fmt.Errorf("failed to query branch commit dates: %w", err)
+		}
+
+		for _, node := range refs.Repository.Refs.Nodes {
+			result[node.Name] = node.Target.CommittedDate
+		}
+		if !refs.Repository.Refs.PageInfo.HasNextPage {
+			break
+		}
+		vars["cursor"] = refs.Repository.Refs.PageInfo.EndCursor
+		refs.Repository.Refs.Nodes = nil
+	}
+
+	return result, nil
+}
+
+// OpenMergeRequestCount returns the number of open pull requests of a repository
+func OpenMergeRequestCount(repo api.Repository, githubClient *github.Client) (int, error) {
+	// request a single item per page, the last page number is the total count
+	data, resp, err := githubClient.PullRequests.List(context.Background(), repo.Namespace, repo.Name, &github.PullRequestListOptions{
+		State:       "open",
+		ListOptions: github.ListOptions{PerPage: 1},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+	if resp.LastPage == 0 {
+		return len(data), nil
+	}
+
+	return resp.LastPage, nil
+}
+
+// ListFiles returns the entries of the tree at the given path, using the Trees API
+func ListFiles(repo api.Repository, githubClient *github.Client, ref string, dir string, recursive bool) ([]api.TreeEntry, error) {
+	var result []api.TreeEntry
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+	dir = strings.Trim(dir, "/")
+
+	// resolve the tree of the requested directory
+	treeSHA := ref
+	if dir != "" {
+		for _, segment := range strings.Split(dir, "/") {
+			tree, _, err := githubClient.Git.GetTree(context.Background(), repo.Namespace, repo.Name, treeSHA, false)
+			if err != nil {
+				return result, toTreeError(err)
+			}
+
+			found := false
+			for _, e := range tree.Entries {
+				if e.GetPath() == segment && e.GetType() == "tree" {
+					treeSHA = e.GetSHA()
+					found = true
+					break
+				}
+			}
+			if !found {
+				return result, fmt.Errorf("directory %s not found in %s: %w", dir, ref, api.ErrNotFound)
+			}
+		}
+	}
+
+	tree, _, err := githubClient.Git.GetTree(context.Background(), repo.Namespace, repo.Name, treeSHA, recursive)
+	if err != nil {
+		return result, toTreeError(err)
+	}
+	for _, e := range tree.Entries {
+		result = append(result, api.TreeEntry{
+			Path: path.Join(dir, e.GetPath()),
+			Name: path.Base(e.GetPath()),
+			Type: api.TreeEntryType(e.GetType()),
+			Mode: e.GetMode(),
+			Size: int64(e.GetSize()),
+			SHA:  e.GetSHA(),
+		})
+	}
+	if tree.GetTruncated() {
+		return result, fmt.Errorf("tree listing of %s in %s exceeds the github api limit: %w", dir, ref, api.ErrTruncated)
+	}
+
+	return result, nil
+}
+
+func toTreeError(err error) error {
+	if IsNotFound(err) {
+		return fmt.Errorf("failed to get tree: %w", api.ErrNotFound)
+	}
+
+	return fmt.Errorf("failed to get tree: %w", err)
+}
+
+// File returns the content and metadata of a file, the mode is resolved from the parent tree and the content is fetched in the same graphql query.
+// The raw blob is only downloaded for binary files and files that are too large for the graphql api.
+func File(repo api.Repository, githubClient *github.Client, ref string, filePath string) (api.File, error) {
+	filePath = strings.Trim(filePath, "/")
+	result := api.File{Path: filePath}
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+
+	dir := path.Dir(filePath)
+	if dir == "." {
+		dir = ""
+	}
+
+	var data struct {
+		Repository struct {
+			Parent *struct {
+				Entries []struct {
+					Path string `json:"path"`
+					Mode int    `json:"mode"`
+					Type string `json:"type"`
+					Oid  string `json:"oid"`
+				} `json:"entries"`
+			} `json:"parent"`
+			File *struct {
+				ByteSize    int64   `json:"byteSize"`
+				IsBinary    bool    `json:"isBinary"`
+				IsTruncated bool    `json:"isTruncated"`
+				Text        *string `json:"text"`
+			} `json:"file"`
+		} `json:"repository"`
+	}
+	query := `query($owner: String!, $name: String!, $parent: String!, $file: String!) { repository(owner: $owner, name: $name) { parent: object(expression: $parent) { ... on Tree { entries { path mode type oid } } } file: object(expression: $file) { ... on Blob { byteSize isBinary isTruncated text } } } }`
+	err := GraphQL(githubClient, query, map[string]any{"owner": repo.Namespace, "name": repo.Name, "parent": ref + ":" + dir, "file": ref + ":" + filePath}, &data)
+	if err != nil {
+		return result, fmt.Errorf("failed to query file %s: %w", filePath, err)
+	}
+
+	found := false
+	if data.Repository.Parent != nil {
+		for _, e := range data.Repository.Parent.Entries {
+			if e.Path == filePath && e.Type != string(api.TreeEntryTypeTree) {
+				result.SHA = e.Oid
+				result.Mode = fmt.Sprintf("%06o", e.Mode)
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return result, fmt.Errorf("file %s not found: %w", filePath, api.ErrNotFound)
+	}
+
+	// submodules do not have a blob
+	blob := data.Repository.File
+	if blob == nil {
+		return result, nil
+	}
+	result.Size = blob.ByteSize
+
+	// the text is only usable if it is complete and was not altered by decoding, e.g. for invalid utf-8
+	if !blob.IsBinary && !blob.IsTruncated && blob.Text != nil && int64(len(*blob.Text)) == blob.ByteSize {
+		result.Content = []byte(*blob.Text)
+		return result, nil
+	}
+
+	content, _, err := githubClient.Git.GetBlobRaw(context.Background(), repo.Namespace, repo.Name, result.SHA)
+	if err != nil {
+		return result, fmt.Errorf("failed to get blob %s: %w", result.SHA, err)
+	}
+	result.Content = content
+
+	return result, nil
+}
+
+// FileExists checks if a file exists
+func FileExists(repo api.Repository, githubClient *github.Client, ref string, filePath string) (bool, error) {
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+
+	fileContent, _, _, err := githubClient.Repositories.GetContents(context.Background(), repo.Namespace, repo.Name, filePath, &github.RepositoryContentGetOptions{
+		Ref: ref,
+	})
+	if err != nil {
+		if IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get file content: %w", err)
+	}
+
+	return fileContent != nil, nil
+}
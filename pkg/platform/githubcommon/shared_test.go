@@ -0,0 +1,101 @@
+package githubcommon
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+)
+
+func TestBranches(t *testing.T) {
+	requests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/cidverse/go-vcsapp/branches", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprint(w, `[{"name":"main","commit":{"sha":"a1"},"protected":true},{"name":"feature","commit":{"sha":"b2"}}]`)
+	})
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		_, _ = fmt.Fprint(w, `{"data":{"repository":{"refs":{"nodes":[{"name":"main","target":{"committedDate":"2024-01-02T03:04:05Z"}},{"name":"feature","target":{"committedDate":"2024-02-03T04:05:06Z"}}],"pageInfo":{"hasNextPage":false}}}}}`)
+	})
+
+	result, err := Branches(api.Repository{Namespace: "cidverse", Name: "go-vcsapp", DefaultBranch: "main"}, newTestClient(t, mux))
+	if err != nil {
+		t.Fatalf("expected no error, but got %v", err)
+	}
+	if len(result) != 2 || !result[0].IsDefault || !result[0].IsProtected || result[1].CommitDate == nil || result[1].CommitDate.Month() != 2 {
+		t.Errorf("expected branches with commit dates, but got %+v", result)
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, but got %d", requests)
+	}
+}
+
+func TestListFilesTruncated(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /repos/cidverse/go-vcsapp/git/trees/main", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprint(w, `{"sha":"t1","truncated":true,"tree":[{"path":"README.md","type":"blob","mode":"100644","sha":"b1"}]}`)
+	})
+
+	result, err := ListFiles(api.Repository{Namespace: "cidverse", Name: "go-vcsapp", DefaultBranch: "main"}, newTestClient(t, mux), "", "", true)
+	if !errors.Is(err, api.ErrTruncated) {
+		t.Errorf("expected truncated error, but got %v", err)
+	}
+	if len(result) != 1 || result[0].Path != "README.md" {
+		t.Errorf("expected partial result, but got %+v", result)
+	}
+}
+
+func TestFile(t *testing.T) {
+	blobRequests := 0
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /graphql", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Variables map[string]string `json:"variables"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		files := map[string]string{
+			"main:bin/run.sh":    `{"byteSize":10,"isBinary":false,"isTruncated":false,"text":"#!/bin/sh\n"}`,
+			"main:bin/image.png": `{"byteSize":4,"isBinary":true,"isTruncated":false,"text":null}`,
+		}
+		file, ok := files[body.Variables["file"]]
+		if !ok {
+			file = "null"
+		}
+		_, _ = fmt.Fprintf(w, `{"data":{"repository":{"parent":{"entries":[{"path":"bin/run.sh","mode":33261,"type":"blob","oid":"b1"},{"path":"bin/image.png","mode":33188,"type":"blob","oid":"b2"}]},"file":%s}}}`, file)
+	})
+	mux.HandleFunc("GET /repos/cidverse/go-vcsapp/git/blobs/b2", func(w http.ResponseWriter, r *http.Request) {
+		blobRequests++
+		_, _ = w.Write([]byte{0x89, 'P', 0x00, 0x01})
+	})
+	client := newTestClient(t, mux)
+	repo := api.Repository{Namespace: "cidverse", Name: "go-vcsapp", DefaultBranch: "main"}
+
+	testCases := []struct {
+		name         string
+		path         string
+		expectedMode string
+		expectedSize int64
+		expectedBlob int
+		expectedErr  error
+	}{
+		{"text file", "bin/run.sh", "100755", 10, 0, nil},
+		{"binary file", "bin/image.png", "100644", 4, 1, nil},
+		{"missing file", "bin/missing.sh", "", 0, 1, api.ErrNotFound},
+	}
+
+	for _, tc := range testCases {
+		result, err := File(repo, client, "", tc.path)
+		if !errors.Is(err, tc.expectedErr) || (err == nil && tc.expectedErr != nil) {
+			t.Errorf("For case %s, expected error %v, but got %v", tc.name, tc.expectedErr, err)
+			continue
+		}
+		if result.Mode != tc.expectedMode || result.Size != tc.expectedSize || int64(len(result.Content)) != tc.expectedSize || blobRequests != tc.expectedBlob {
+			t.Errorf("For case %s, expected mode %s, size %d and %d blob requests, but got %s, %d (%d bytes) and %d", tc.name, tc.expectedMode, tc.expectedSize, tc.expectedBlob, result.Mode, result.Size, len(result.Content), blobRequests)
+		}
+	}
+}
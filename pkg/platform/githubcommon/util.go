@@ -2,16 +2,26 @@ package githubcommon
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/bradleyfalzon/ghinstallation/v2"
-	"github.com/cidverse/go-ptr"
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/google/go-github/v88/github"
 )
 
+// SplitRepositoryPath splits a repository path (e.g. organization/repo) into owner and name
+func SplitRepositoryPath(path string) (string, string, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid repository path: %s", path)
+	}
+
+	return parts[0], parts[1], nil
+}
+
 // BranchSliceToNameSlice converts a slice of branches to a slice of branch names
 func BranchSliceToNameSlice(branches []*github.Branch) []string {
 	var branchNames []string
@@ -22,6 +32,23 @@ func BranchSliceToNameSlice(branches []*github.Branch) []string {
 	return branchNames
 }
 
+// ToRepositoryVisibility returns the visibility of a repository, falling back to the private flag if the visibility is not provided
+func ToRepositoryVisibility(repo *github.Repository) api.RepositoryVisibility {
+	switch strings.ToLower(repo.GetVisibility()) {
+	case "public":
+		return api.RepositoryVisibilityPublic
+	case "internal":
+		return api.RepositoryVisibilityInternal
+	case "private":
+		return api.RepositoryVisibilityPrivate
+	}
+
+	if repo.GetPrivate() {
+		return api.RepositoryVisibilityPrivate
+	}
+	return api.RepositoryVisibilityPublic
+}
+
 func ToMergeRequestLabels(labels []*github.Label) []string {
 	var labelNames []string
 	for _, label := range labels {
@@ -39,12 +66,128 @@ func ToStandardMergeRequestState(state string) api.MergeRequestState {
 	return api.MergeRequestStateClosed
 }
 
-func ToMergeMethod(mergeStrategyOptions api.MergeStrategyOptions) string {
-	if ptr.ValueOrDefault(mergeStrategyOptions.Squash, false) {
-		return "squash"
+// ToMergeMethod returns the merge method for the REST API, an empty string selects the default of the repository
+func ToMergeMethod(mergeStrategyOptions api.MergeStrategyOptions) (string, error) {
+	switch method := api.ResolveMergeMethod(mergeStrategyOptions); method {
+	case "":
+		return "", nil
+	case api.MergeMethodMerge, api.MergeMethodSquash, api.MergeMethodRebase:
+		return string(method), nil
+	case api.MergeMethodFastForward:
+		return "", fmt.Errorf("merge method %s is not supported by GitHub", method)
+	default:
+		return "", fmt.Errorf("unsupported merge method: %s", method)
+	}
+}
+
+// ToAutoMergeMethod returns the GraphQL PullRequestMergeMethod for the merge strategy
+func ToAutoMergeMethod(mergeStrategyOptions api.MergeStrategyOptions) (string, error) {
+	method, err := ToMergeMethod(mergeStrategyOptions)
+	if err != nil {
+		return "", err
+	}
+	if method == "" {
+		return "MERGE", nil
+	}
+
+	return strings.ToUpper(method), nil
+}
+
+// ToCheckRunState converts the status and conclusion of a check run into a pipeline state, see https://docs.github.com/en/rest/checks/runs
+func ToCheckRunState(status string, conclusion string) api.PipelineState {
+	switch status {
+	case "queued", "requested", "waiting", "pending":
+		return api.PipelineStatePending
+	case "in_progress":
+		return api.PipelineStateRunning
+	}
+
+	switch conclusion {
+	case "success", "neutral":
+		return api.PipelineStateSuccess
+	case "skipped":
+		return api.PipelineStateSkipped
+	case "cancelled":
+		return api.PipelineStateCanceled
+	case "action_required":
+		return api.PipelineStateManual
+	case "failure", "timed_out", "startup_failure":
+		return api.PipelineStateFailed
+	}
+
+	return api.PipelineStateUnknown
+}
+
+// ToCommitStatusState converts the state of a commit status into a pipeline state
+func ToCommitStatusState(state string) api.PipelineState {
+	switch state {
+	case "pending":
+		return api.PipelineStatePending
+	case "success":
+		return api.PipelineStateSuccess
+	case "failure", "error":
+		return api.PipelineStateFailed
+	}
+
+	return api.PipelineStateUnknown
+}
+
+// ToMergeRequest converts a pull request into a merge request
+func ToMergeRequest(pr *github.PullRequest, repo api.Repository) api.MergeRequest {
+	result := api.MergeRequest{
+		Id:                 pr.GetID(),
+		Number:             pr.GetNumber(),
+		Title:              pr.GetTitle(),
+		Description:        pr.GetBody(),
+		Labels:             ToMergeRequestLabels(pr.Labels),
+		Assignees:          ToStandardUsers(pr.Assignees),
+		Reviewers:          ToStandardUsers(pr.RequestedReviewers),
+		Milestone:          pr.GetMilestone().GetTitle(),
+		SourceBranch:       pr.GetHead().GetRef(),
+		TargetBranch:       pr.GetBase().GetRef(),
+		State:              ToStandardMergeRequestState(pr.GetState()),
+		IsMerged:           pr.GetMerged(),
+		IsLocked:           pr.GetLocked(),
+		IsDraft:            pr.GetDraft(),
+		HasConflicts:       pr.GetMergeableState() == "dirty", // see https://docs.github.com/en/graphql/reference/enums#mergestatestatus
+		CanMerge:           pr.GetMergeable(),
+		Author:             ToStandardUser(pr.GetUser()),
+		WebURL:             pr.GetHTMLURL(),
+		HeadSHA:            pr.GetHead().GetSHA(),
+		CreatedAt:          pr.CreatedAt.GetTime(),
+		UpdatedAt:          pr.UpdatedAt.GetTime(),
+		MergedAt:           pr.MergedAt.GetTime(),
+		Repository:         repo,
+		IsAutoMergeEnabled: pr.AutoMerge != nil,
+	}
+	for _, team := range pr.RequestedTeams {
+		result.Reviewers = append(result.Reviewers, api.User{Username: repo.Namespace + "/" + team.GetSlug(), Name: team.GetName()})
+	}
+	// maintainer edits only apply to pull requests from forks
+	if pr.GetHead().GetRepo().GetID() != pr.GetBase().GetRepo().GetID() {
+		result.AllowMaintainerEdit = pr.MaintainerCanModify
+	}
+
+	return result
+}
+
+func ToMergeRequestComment(comment *github.IssueComment) api.MergeRequestComment {
+	return api.MergeRequestComment{
+		Id:        comment.GetID(),
+		Body:      comment.GetBody(),
+		Author:    ToStandardUser(comment.User),
+		CreatedAt: comment.CreatedAt.GetTime(),
+		UpdatedAt: comment.UpdatedAt.GetTime(),
+	}
+}
+
+func ToStandardUsers(users []*github.User) []api.User {
+	var result []api.User
+	for _, user := range users {
+		result = append(result, ToStandardUser(user))
 	}
 
-	return ""
+	return result
 }
 
 func ToStandardUser(user *github.User) api.User {
@@ -70,6 +213,26 @@ func ToStandardUser(user *github.User) api.User {
 	}
 }
 
+// IsNotFound returns true if the error is a 404 response from the GitHub API
+func IsNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode == http.StatusNotFound
+	}
+
+	return false
+}
+
+// IsForbidden checks if the error is a 403, e.g. when the token lacks the permissions to read repository settings
+func IsForbidden(err error) bool {
+	var errResp *github.ErrorResponse
+	if errors.As(err, &errResp) && errResp.Response != nil {
+		return errResp.Response.StatusCode == http.StatusForbidden
+	}
+
+	return false
+}
+
 // RoundTripperToAccessToken takes a ghinstallation round-tripper and obtains a new access token
 func RoundTripperToAccessToken(rt http.RoundTripper) (string, error) {
 	if rt == nil {
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/cidverse/go-vcsapp/pkg/platform/githubcommon"
 	"github.com/google/go-github/v88/github"
 )
 
@@ -27,6 +28,14 @@ func convertRepository(repo *github.Repository, client *github.Client) api.Repos
 		IsPersonalProject: strings.EqualFold(repo.GetOwner().GetType(), "user"),
 		Topics:            repo.Topics,
 		CreatedAt:         repo.CreatedAt.GetTime(),
+		Visibility:        githubcommon.ToRepositoryVisibility(repo),
+		IsArchived:        repo.GetArchived(),
+		Language:          repo.GetLanguage(),
+		StarCount:         repo.GetStargazersCount(),
+		ForkCount:         repo.GetForksCount(),
+		Size:              int64(repo.GetSize()),
+		LastActivityAt:    repo.PushedAt.GetTime(),
+		WebURL:            repo.GetHTMLURL(),
 		InternalClient:    client,
 		InternalRepo:      repo,
 	}
@@ -0,0 +1,223 @@
+package gitlabuser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/rs/zerolog/log"
+	"gitlab.com/gitlab-org/api/client-go/v2"
+)
+
+// pushCommitsAPI creates a sequence of commits using the Commits API, the commits are attributed to the user of the access token
+func (n Platform) pushCommitsAPI(repo api.Repository, base string, branch string, commits []api.Commit, dir string) error {
+	// get all changed files in directory
+	r, err := git.PlainOpen(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open repository: %w", err)
+	}
+	w, err := r.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	status, err := w.Status()
+	if err != nil {
+		return fmt.Errorf("failed to get status: %w", err)
+	}
+	idx, err := r.Storer.Index()
+	if err != nil {
+		return fmt.Errorf("failed to read index: %w", err)
+	}
+	lineEndings, err := api.NewLineEndingPolicy(dir)
+	if err != nil {
+		return err
+	}
+	var changedFiles []string
+	for file := range status {
+		changedFiles = append(changedFiles, file)
+	}
+
+	// the commits are attributed to the token user, use the configured author instead if a sign-off is required so that both match
+	signOff := api.RequiresSignOff(commits)
+	commits = api.SignOffCommits(commits, n.author)
+
+	parent := base
+	for i, files := range api.AssignCommitPaths(changedFiles, commits) {
+		if len(files) == 0 {
+			log.Debug().Str("message", commits[i].Message).Msg("no changes for commit, skipping")
+			continue
+		}
+
+		actions, err := commitActions(dir, idx, status, lineEndings, files)
+		if err != nil {
+			return err
+		}
+		if len(actions) == 0 {
+			// e.g. line ending changes that are removed by normalization, the api rejects commits without actions
+			log.Debug().Str("message", commits[i].Message).Msg("no actions for commit, skipping")
+			continue
+		}
+
+		opts := &gitlab.CreateCommitOptions{
+			Branch:        ptr.Ptr(branch),
+			CommitMessage: ptr.Ptr(api.CommitMessage(commits[i])),
+			StartSHA:      ptr.Ptr(parent),
+			Actions:       actions,
+			Force:         ptr.True(),
+		}
+		if signOff {
+			opts.AuthorName = ptr.Ptr(n.author.Name)
+			opts.AuthorEmail = ptr.Ptr(n.author.Email)
+		}
+		commit, _, err := n.client.Commits.CreateCommit(int(repo.Id), opts)
+		if err != nil {
+			return fmt.Errorf("failed to create commit: %w", err)
+		}
+		parent = commit.ID
+	}
+	if parent == base {
+		return fmt.Errorf("no changes to commit")
+	}
+
+	return nil
+}
+
+// commitActions converts the changed files into Commits API actions, deleted and added files with identical content are converted into moves
+func commitActions(dir string, idx *index.Index, status git.Status, lineEndings api.LineEndingPolicy, files []string) ([]*gitlab.CommitActionOptions, error) {
+	var actions []*gitlab.CommitActionOptions
+	var deleted []*index.Entry
+	var added []string
+
+	for _, file := range files {
+		entry, _ := idx.Entry(file)
+		info, err := os.Lstat(filepath.Join(dir, file))
+		if os.IsNotExist(err) {
+			if entry != nil {
+				deleted = append(deleted, entry)
+			}
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to get file stats: %w", err)
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil, fmt.Errorf("symlinks are not supported by the commits api: %s", file)
+		}
+		if info.IsDir() {
+			return nil, fmt.Errorf("submodules are not supported by the commits api: %s", file)
+		}
+
+		if entry == nil || status.File(file).Staging == git.Added {
+			added = append(added, file)
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		content = lineEndings.Normalize(file, content)
+		if plumbing.ComputeHash(plumbing.BlobObject, content) != entry.Hash {
+			actions = append(actions, contentAction(gitlab.FileUpdate, file, content))
+		}
+		if executable := info.Mode()&0111 != 0; executable != (entry.Mode == filemode.Executable) {
+			actions = append(actions, chmodAction(file, executable))
+		}
+	}
+
+	for _, file := range added {
+		content, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read file: %w", err)
+		}
+		content = lineEndings.Normalize(file, content)
+		info, err := os.Stat(filepath.Join(dir, file))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get file stats: %w", err)
+		}
+		executable := info.Mode()&0111 != 0
+
+		hash := plumbing.ComputeHash(plumbing.BlobObject, content)
+		i := slices.IndexFunc(deleted, func(e *index.Entry) bool { return e.Hash == hash })
+		if i == -1 {
+			actions = append(actions, contentAction(gitlab.FileCreate, file, content))
+			if executable {
+				actions = append(actions, chmodAction(file, true))
+			}
+			continue
+		}
+
+		actions = append(actions, &gitlab.CommitActionOptions{
+			Action:       ptr.Ptr(gitlab.FileMove),
+			FilePath:     ptr.Ptr(file),
+			PreviousPath: ptr.Ptr(deleted[i].Name),
+		})
+		if executable != (deleted[i].Mode == filemode.Executable) {
+			actions = append(actions, chmodAction(file, executable))
+		}
+		deleted = slices.Delete(deleted, i, i+1)
+	}
+
+	for _, entry := range deleted {
+		actions = append(actions, &gitlab.CommitActionOptions{
+			Action:   ptr.Ptr(gitlab.FileDelete),
+			FilePath: ptr.Ptr(entry.Name),
+		})
+	}
+
+	return actions, nil
+}
+
+// normalizeLineEndings rewrites a regular file in the worktree with the line endings that should be committed
+func normalizeLineEndings(dir string, file string, lineEndings api.LineEndingPolicy) error {
+	filePath := filepath.Join(dir, file)
+	info, err := os.Lstat(filePath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to get file stats: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return nil
+	}
+
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	normalized := lineEndings.Normalize(file, content)
+	if len(normalized) == len(content) {
+		return nil
+	}
+	if err = os.WriteFile(filePath, normalized, info.Mode().Perm()); err != nil {
+		return fmt.Errorf("failed to write file %s: %w", file, err)
+	}
+
+	return nil
+}
+
+// chmodAction creates an action that sets or removes the executable flag
+func chmodAction(file string, executable bool) *gitlab.CommitActionOptions {
+	return &gitlab.CommitActionOptions{
+		Action:          ptr.Ptr(gitlab.FileChmod),
+		FilePath:        ptr.Ptr(file),
+		ExecuteFilemode: ptr.Ptr(executable),
+	}
+}
+
+// contentAction creates a create or update action, the content is base64 encoded to preserve binary files
+func contentAction(action gitlab.FileActionValue, file string, content []byte) *gitlab.CommitActionOptions {
+	return &gitlab.CommitActionOptions{
+		Action:   ptr.Ptr(action),
+		FilePath: ptr.Ptr(file),
+		Content:  ptr.Ptr(base64.StdEncoding.EncodeToString(content)),
+		Encoding: ptr.Ptr("base64"),
+	}
+}
@@ -0,0 +1,119 @@
+package gitlabuser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"gitlab.com/gitlab-org/api/client-go/v2"
+)
+
+func TestCommitActions(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	w, _ := r.Worktree()
+	files := map[string]string{
+		".gitattributes": "*.txt text\n",
+		"old.txt":        "moved content\n",
+		"run.sh":         "#!/bin/sh\n",
+		"crlf.txt":       "a\nb\n",
+		"removed.txt":    "removed\n",
+		"update.txt":     "before\n",
+	}
+	for name, content := range files {
+		if err = os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+	}
+	_, _ = w.Add(".")
+	if _, err = w.Commit("initial", &git.CommitOptions{Author: &object.Signature{Name: "vcs-app", Email: "vcs-app@localhost"}}); err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+
+	// move, chmod-only, crlf-only, delete and update
+	_ = os.Rename(filepath.Join(dir, "old.txt"), filepath.Join(dir, "new.txt"))
+	_ = os.Chmod(filepath.Join(dir, "run.sh"), 0755)
+	_ = os.WriteFile(filepath.Join(dir, "crlf.txt"), []byte("a\r\nb\r\n"), 0644)
+	_ = os.Remove(filepath.Join(dir, "removed.txt"))
+	_ = os.WriteFile(filepath.Join(dir, "update.txt"), []byte("after\n"), 0644)
+
+	status, _ := w.Status()
+	idx, _ := r.Storer.Index()
+	lineEndings, err := api.NewLineEndingPolicy(dir)
+	if err != nil {
+		t.Fatalf("failed to read line ending policy: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		files    []string
+		expected []string
+	}{
+		{"move", []string{"old.txt", "new.txt"}, []string{"move old.txt -> new.txt"}},
+		{"chmod only", []string{"run.sh"}, []string{"chmod run.sh true"}},
+		{"crlf only", []string{"crlf.txt"}, nil},
+		{"delete", []string{"removed.txt"}, []string{"delete removed.txt"}},
+		{"update", []string{"update.txt"}, []string{"update update.txt YWZ0ZXIK"}},
+	}
+
+	for _, tc := range testCases {
+		actions, err := commitActions(dir, idx, status, lineEndings, tc.files)
+		if err != nil {
+			t.Fatalf("For case %s, expected no error, but got %v", tc.name, err)
+		}
+
+		var result []string
+		for _, a := range actions {
+			result = append(result, formatAction(a))
+		}
+		if !slices.Equal(result, tc.expected) {
+			t.Errorf("For case %s, expected %v, but got %v", tc.name, tc.expected, result)
+		}
+	}
+}
+
+func TestCommitActionsUnsupported(t *testing.T) {
+	dir := t.TempDir()
+	r, err := git.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repository: %v", err)
+	}
+	w, _ := r.Worktree()
+	_ = os.WriteFile(filepath.Join(dir, "target.txt"), []byte("target\n"), 0644)
+	_ = os.Symlink("target.txt", filepath.Join(dir, "link.txt"))
+	if _, err = git.PlainInit(filepath.Join(dir, "submodule"), false); err != nil {
+		t.Fatalf("failed to init submodule: %v", err)
+	}
+
+	status, _ := w.Status()
+	idx, _ := r.Storer.Index()
+	lineEndings, _ := api.NewLineEndingPolicy(dir)
+
+	for _, file := range []string{"link.txt", "submodule"} {
+		_, err = commitActions(dir, idx, status, lineEndings, []string{file})
+		if err == nil {
+			t.Errorf("For file %s, expected unsupported error, but got nil", file)
+		}
+	}
+}
+
+func formatAction(a *gitlab.CommitActionOptions) string {
+	switch *a.Action {
+	case gitlab.FileMove:
+		return fmt.Sprintf("move %s -> %s", *a.PreviousPath, *a.FilePath)
+	case gitlab.FileChmod:
+		return fmt.Sprintf("chmod %s %t", *a.FilePath, *a.ExecuteFilemode)
+	case gitlab.FileDelete:
+		return fmt.Sprintf("delete %s", *a.FilePath)
+	default:
+		return fmt.Sprintf("%s %s %s", *a.Action, *a.FilePath, *a.Content)
+	}
+}
@@ -27,11 +27,20 @@ func convertRepository(repo *gitlab.Project) api.Repository {
 		Topics:            repo.Topics,
 		LicenseURL:        repo.LicenseURL,
 		CreatedAt:         repo.CreatedAt,
+		Visibility:        toRepositoryVisibility(repo.Visibility),
+		IsArchived:        repo.Archived,
+		StarCount:         int(repo.StarCount),
+		ForkCount:         int(repo.ForksCount),
+		LastActivityAt:    repo.LastActivityAt,
+		WebURL:            repo.WebURL,
 		InternalRepo:      repo,
 	}
 	if repo.License != nil {
 		r.LicenseName = repo.License.Name
 	}
+	if repo.Statistics != nil {
+		r.Size = repo.Statistics.RepositorySize / 1024
+	}
 
 	return r
 }
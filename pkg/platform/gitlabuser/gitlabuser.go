@@ -1,8 +1,12 @@
 package gitlabuser
 
 import (
-	"encoding/base64"
+	"errors"
 	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/cidverse/go-ptr"
@@ -19,6 +23,8 @@ const pageSize = 100
 type Platform struct {
 	accessToken string
 	author      api.GitAuthor
+	signer      git.Signer
+	commitsAPI  bool
 	client      *gitlab.Client
 }
 
@@ -27,6 +33,8 @@ type Config struct {
 	Username    string
 	AccessToken string
 	Author      api.GitAuthor
+	Signing     api.SigningConfig // Signing is used to sign commits created by CommitAndPush
+	CommitsAPI  bool              // CommitsAPI creates commits using the Commits API instead of pushing them with git, can not be combined with Signing
 }
 
 func (n Platform) Name() string {
@@ -46,6 +54,7 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 		MinAccessLevel: ptr.Ptr(gitlab.MaintainerPermissions),
 		Membership:     ptr.True(),
 		Archived:       ptr.False(),
+		Statistics:     ptr.True(),
 		ListOptions: gitlab.ListOptions{
 			PerPage: pageSize,
 		},
@@ -79,9 +88,9 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 
 		// branches
 		if opts.IncludeBranches && !r.IsEmpty {
-			branchList, _, err := n.client.Branches.ListBranches(repo.ID, &gitlab.ListBranchesOptions{})
+			branchList, err := n.listBranches(repo.ID)
 			if err != nil {
-				return result, fmt.Errorf("failed to list branches: %w", err)
+				return result, err
 			}
 
 			r.Branches = branchSliceToNameSlice(branchList)
@@ -92,6 +101,24 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 			r.Plan = "free"
 		}
 
+		// language
+		if opts.IncludeLanguage && !r.IsEmpty {
+			languages, _, err := n.client.Projects.GetProjectLanguages(repo.ID, nil)
+			if err != nil {
+				return result, fmt.Errorf("failed to get languages: %w", err)
+			}
+			r.Language = primaryLanguage(*languages)
+		}
+
+		// merge requests
+		if opts.IncludeMergeRequestCount {
+			count, err := n.openMergeRequestCount(repo.ID)
+			if err != nil {
+				return result, err
+			}
+			r.OpenMergeRequestCount = count
+		}
+
 		result = append(result, r)
 	}
 
@@ -99,14 +126,82 @@ func (n Platform) Repositories(opts api.RepositoryListOpts) ([]api.Repository, e
 }
 
 func (n Platform) FindRepository(path string) (api.Repository, error) {
-	repo, _, err := n.client.Projects.GetProject(path, &gitlab.GetProjectOptions{License: gitlab.Ptr(true)})
-	if err != nil {
+	// the project can be referenced by id or path, numeric references are looked up as path if no project with that id exists
+	// (GitLab resolves canonical numeric references as id only, so the path lookup is skipped for those)
+	opts := &gitlab.GetProjectOptions{License: gitlab.Ptr(true), Statistics: gitlab.Ptr(true)}
+	id, repoPath := api.ParseRepositoryReference(path)
+	var repo *gitlab.Project
+	var err error
+	if id != 0 {
+		repo, _, err = n.client.Projects.GetProject(id, opts)
+	}
+	if id == 0 || (errors.Is(err, gitlab.ErrNotFound) && repoPath != strconv.FormatInt(id, 10)) {
+		repo, _, err = n.client.Projects.GetProject(repoPath, opts)
+	}
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return api.Repository{}, fmt.Errorf("repository %s not found: %w", path, api.ErrNotFound)
+	} else if err != nil {
 		return api.Repository{}, fmt.Errorf("failed to get repository: %w", err)
 	}
 
 	return convertRepository(repo), nil
 }
 
+func (n Platform) Branches(repo api.Repository) ([]api.Branch, error) {
+	var result []api.Branch
+
+	branches, err := n.listBranches(repo.Id)
+	if err != nil {
+		return result, err
+	}
+
+	for _, b := range branches {
+		result = append(result, toBranch(b))
+	}
+
+	return result, nil
+}
+
+// openMergeRequestCount returns the number of open merge requests of a project
+func (n Platform) openMergeRequestCount(projectId int64) (int, error) {
+	// request a single item, the total count is provided in the response headers
+	_, resp, err := n.client.MergeRequests.ListProjectMergeRequests(projectId, &gitlab.ListProjectMergeRequestsOptions{
+		State: ptr.Ptr("opened"),
+		ListOptions: gitlab.ListOptions{
+			PerPage: 1,
+		},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to list merge requests: %w", err)
+	}
+
+	return int(resp.TotalItems), nil
+}
+
+// listBranches returns all branches of a project, following pagination
+func (n Platform) listBranches(projectId int64) ([]*gitlab.Branch, error) {
+	var branches []*gitlab.Branch
+
+	opts := &gitlab.ListBranchesOptions{
+		ListOptions: gitlab.ListOptions{
+			PerPage: pageSize,
+		},
+	}
+	for {
+		data, resp, err := n.client.Branches.ListBranches(projectId, opts)
+		if err != nil {
+			return branches, fmt.Errorf("failed to list branches: %w", err)
+		}
+		branches = append(branches, data...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return branches, nil
+}
+
 func (n Platform) MergeRequests(repo api.Repository, options api.MergeRequestSearchOptions) ([]api.MergeRequest, error) {
 	var result []api.MergeRequest
 
@@ -144,62 +239,278 @@ func (n Platform) MergeRequests(repo api.Repository, options api.MergeRequestSea
 	}
 
 	for _, pr := range mergeRequests {
-		entry := api.MergeRequest{
-			Id:           pr.ID,
-			Number:       int(pr.IID),
-			Title:        pr.Title,
-			Description:  pr.Description,
-			Labels:       toMergeRequestLabels(pr.Labels),
-			SourceBranch: pr.SourceBranch,
-			TargetBranch: pr.TargetBranch,
-			State:        toMergeRequestState(pr.State),
-			IsMerged:     pr.MergedAt != nil,
-			IsLocked:     pr.DiscussionLocked,
-			IsDraft:      pr.Draft,
-			HasConflicts: pr.HasConflicts,
-			CanMerge:     pr.DetailedMergeStatus == "mergeable", // see https://docs.gitlab.com/ee/api/merge_requests.html#merge-status
-			Author:       toUser(pr.Author),
-			Repository:   repo,
-		}
-		entry.PipelineState = api.PipelineStateUnknown // list request does not provide pipeline status in Pipeline.Status
-
-		result = append(result, entry)
+		mr := toMergeRequest(pr, repo)
+		if options.IncludePipelineState {
+			var err error
+			mr.PipelineState, mr.Checks, err = n.pipelineState(repo, pr.IID)
+			if err != nil {
+				return result, err
+			}
+		}
+
+		result = append(result, mr)
+	}
+
+	return result, nil
+}
+
+// pipelineState returns the state of the head pipeline of a merge request, together with the state of each job
+func (n Platform) pipelineState(repo api.Repository, mergeRequestIID int64) (api.PipelineState, []api.MergeRequestCheck, error) {
+	mr, _, err := n.client.MergeRequests.GetMergeRequest(int(repo.Id), mergeRequestIID, nil)
+	if err != nil {
+		return api.PipelineStateUnknown, nil, fmt.Errorf("failed to get merge request: %w", err)
+	}
+	if mr.HeadPipeline == nil {
+		return api.PipelineStateUnknown, nil, nil
+	}
+
+	checks, err := n.pipelineChecks(repo, mr.HeadPipeline.ID)
+	if err != nil {
+		return api.PipelineStateUnknown, checks, err
+	}
+
+	return toPipelineState(mr.HeadPipeline.Status), checks, nil
+}
+
+// pipelineChecks returns the state of each job of a pipeline
+func (n Platform) pipelineChecks(repo api.Repository, pipelineID int64) ([]api.MergeRequestCheck, error) {
+	var checks []api.MergeRequestCheck
+
+	opts := &gitlab.ListJobsOptions{ListOptions: gitlab.ListOptions{PerPage: pageSize}}
+	for {
+		data, resp, err := n.client.Jobs.ListPipelineJobs(int(repo.Id), pipelineID, opts)
+		if err != nil {
+			return checks, fmt.Errorf("failed to list pipeline jobs: %w", err)
+		}
+		for _, job := range data {
+			checks = append(checks, api.MergeRequestCheck{
+				Name:  job.Name,
+				State: toPipelineState(job.Status),
+				URL:   job.WebURL,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return checks, nil
+}
+
+func (n Platform) GetMergeRequest(repo api.Repository, number int) (api.MergeRequest, error) {
+	mr, _, err := n.client.MergeRequests.GetMergeRequest(int(repo.Id), int64(number), nil)
+	if errors.Is(err, gitlab.ErrNotFound) {
+		return api.MergeRequest{}, fmt.Errorf("merge request !%d not found: %w", number, api.ErrNotFound)
+	} else if err != nil {
+		return api.MergeRequest{}, fmt.Errorf("failed to get merge request: %w", err)
+	}
+	result := toMergeRequest(&mr.BasicMergeRequest, repo)
+
+	// commits
+	opts := &gitlab.GetMergeRequestCommitsOptions{ListOptions: gitlab.ListOptions{PerPage: pageSize}}
+	for {
+		data, resp, err := n.client.MergeRequests.GetMergeRequestCommits(int(repo.Id), int64(number), opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to list merge request commits: %w", err)
+		}
+		for _, c := range data {
+			result.Commits = append(result.Commits, api.MergeRequestCommit{
+				Hash:    c.ID,
+				Message: c.Message,
+				Author: api.GitAuthor{
+					Name:  c.AuthorName,
+					Email: c.AuthorEmail,
+				},
+				AuthoredAt: c.AuthoredDate,
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	// approvals
+	approvals, _, err := n.client.MergeRequestApprovals.GetConfiguration(int(repo.Id), int64(number))
+	if err != nil {
+		return result, fmt.Errorf("failed to get merge request approvals: %w", err)
+	}
+	for _, approver := range approvals.ApprovedBy {
+		result.Approvals = append(result.Approvals, toUser(approver.User))
+	}
+
+	// head pipeline
+	if mr.HeadPipeline != nil {
+		result.PipelineState = toPipelineState(mr.HeadPipeline.Status)
+		result.Checks, err = n.pipelineChecks(repo, mr.HeadPipeline.ID)
+		if err != nil {
+			return result, err
+		}
 	}
 
 	return result, nil
 }
 
+func (n Platform) FindMergeRequestByKey(repo api.Repository, key string, openOnly bool) (api.MergeRequest, error) {
+	mr, err := n.findMergeRequestByKey(repo, key, openOnly)
+	if err != nil {
+		return api.MergeRequest{}, err
+	}
+
+	return toMergeRequest(mr, repo), nil
+}
+
+// findMergeRequestByKey searches the merge request descriptions for the key marker, open merge requests take precedence over the most recently updated closed one
+func (n Platform) findMergeRequestByKey(repo api.Repository, key string, openOnly bool) (*gitlab.BasicMergeRequest, error) {
+	if key == "" {
+		return nil, fmt.Errorf("no merge request found for empty key: %w", api.ErrNotFound)
+	}
+
+	state := "all"
+	if openOnly {
+		state = "opened"
+	}
+
+	var closedMatch *gitlab.BasicMergeRequest
+	opts := &gitlab.ListProjectMergeRequestsOptions{
+		State:   ptr.Ptr(state),
+		Search:  ptr.Ptr("vcs-merge-request-key:" + key),
+		OrderBy: ptr.Ptr("updated_at"),
+		Sort:    ptr.Ptr("desc"),
+		ListOptions: gitlab.ListOptions{
+			PerPage: pageSize,
+		},
+	}
+	for {
+		data, resp, err := n.client.MergeRequests.ListProjectMergeRequests(int(repo.Id), opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list merge requests: %w", err)
+		}
+		for _, mr := range data {
+			if api.MergeRequestKey(mr.Description) != key {
+				continue
+			}
+			if mr.State == "opened" {
+				return mr, nil
+			}
+			if closedMatch == nil {
+				closedMatch = mr
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	if closedMatch != nil {
+		return closedMatch, nil
+	}
+	return nil, fmt.Errorf("no merge request found for key %s: %w", key, api.ErrNotFound)
+}
+
 func (n Platform) MergeRequestDiff(repo api.Repository, mergeRequest api.MergeRequest) (api.MergeRequestDiff, error) {
 	result := api.MergeRequestDiff{
 		ChangedFiles: []api.MergeRequestFileDiff{},
 	}
 
-	diff, _, err := n.client.MergeRequests.ListMergeRequestDiffs(int(repo.Id), int64(mergeRequest.Number), &gitlab.ListMergeRequestDiffsOptions{
-		Unidiff: ptr.True(),
+	opts := &gitlab.ListMergeRequestDiffsOptions{
+		ListOptions: gitlab.ListOptions{PerPage: pageSize},
+		Unidiff:     ptr.True(),
+	}
+	for {
+		diff, resp, err := n.client.MergeRequests.ListMergeRequestDiffs(int(repo.Id), int64(mergeRequest.Number), opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to get diff: %w", err)
+		}
+
+		for _, d := range diff {
+			result.ChangedFiles = append(result.ChangedFiles, api.MergeRequestFileDiff{
+				IsNew:       d.NewFile,
+				IsRenamed:   d.RenamedFile,
+				IsDeleted:   d.DeletedFile,
+				OldPath:     d.OldPath,
+				NewPath:     d.NewPath,
+				OldMode:     d.AMode,
+				NewMode:     d.BMode,
+				Diff:        d.Diff,
+				IsBinary:    strings.HasPrefix(d.Diff, "Binary files "),
+				IsTruncated: d.TooLarge || (d.Collapsed && d.Diff == ""),
+				Hunks:       api.ParseDiffHunks(d.Diff),
+			})
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+func (n Platform) MergeRequestComments(repo api.Repository, mergeRequest api.MergeRequest) ([]api.MergeRequestComment, error) {
+	var result []api.MergeRequestComment
+
+	opts := &gitlab.ListMergeRequestNotesOptions{
+		ListOptions: gitlab.ListOptions{PerPage: pageSize},
+		OrderBy:     ptr.Ptr("created_at"),
+		Sort:        ptr.Ptr("asc"),
+	}
+	for {
+		data, resp, err := n.client.Notes.ListMergeRequestNotes(int(repo.Id), int64(mergeRequest.Number), opts)
+		if err != nil {
+			return result, fmt.Errorf("failed to list merge request notes: %w", err)
+		}
+
+		for _, note := range data {
+			result = append(result, toMergeRequestComment(note))
+		}
+
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+
+	return result, nil
+}
+
+func (n Platform) CreateMergeRequestComment(repo api.Repository, mergeRequest api.MergeRequest, body string) (api.MergeRequestComment, error) {
+	note, _, err := n.client.Notes.CreateMergeRequestNote(int(repo.Id), int64(mergeRequest.Number), &gitlab.CreateMergeRequestNoteOptions{
+		Body: &body,
 	})
 	if err != nil {
-		return result, fmt.Errorf("failed to get diff: %w", err)
+		return api.MergeRequestComment{}, fmt.Errorf("failed to create merge request note: %w", err)
 	}
 
-	for _, d := range diff {
-		result.ChangedFiles = append(result.ChangedFiles, api.MergeRequestFileDiff{
-			IsNew:     d.NewFile,
-			IsRenamed: d.RenamedFile,
-			IsDeleted: d.DeletedFile,
-			OldPath:   d.OldPath,
-			NewPath:   d.NewPath,
-			OldMode:   d.AMode,
-			NewMode:   d.BMode,
-			Diff:      d.Diff,
-		})
+	return toMergeRequestComment(note), nil
+}
+
+func (n Platform) UpdateMergeRequestComment(repo api.Repository, mergeRequest api.MergeRequest, commentId int64, body string) (api.MergeRequestComment, error) {
+	note, _, err := n.client.Notes.UpdateMergeRequestNote(int(repo.Id), int64(mergeRequest.Number), commentId, &gitlab.UpdateMergeRequestNoteOptions{
+		Body: &body,
+	})
+	if err != nil {
+		return api.MergeRequestComment{}, fmt.Errorf("failed to update merge request note: %w", err)
 	}
 
-	return result, nil
+	return toMergeRequestComment(note), nil
+}
+
+func (n Platform) DeleteMergeRequestComment(repo api.Repository, mergeRequest api.MergeRequest, commentId int64) error {
+	_, err := n.client.Notes.DeleteMergeRequestNote(int(repo.Id), int64(mergeRequest.Number), commentId)
+	if err != nil {
+		return fmt.Errorf("failed to delete merge request note: %w", err)
+	}
+
+	return nil
 }
 
 func (n Platform) SubmitReview(repo api.Repository, mergeRequest api.MergeRequest, approved bool, message *string) error {
 	if message != nil {
-		_, _, err := n.client.Notes.CreateMergeRequestNote(int(repo.Id), mergeRequest.Id, &gitlab.CreateMergeRequestNoteOptions{
+		_, _, err := n.client.Notes.CreateMergeRequestNote(int(repo.Id), int64(mergeRequest.Number), &gitlab.CreateMergeRequestNoteOptions{
 			Body: message,
 		})
 		if err != nil {
@@ -208,12 +519,12 @@ func (n Platform) SubmitReview(repo api.Repository, mergeRequest api.MergeReques
 	}
 
 	if approved {
-		_, _, err := n.client.MergeRequestApprovals.ApproveMergeRequest(int(repo.Id), mergeRequest.Id, &gitlab.ApproveMergeRequestOptions{})
+		_, _, err := n.client.MergeRequestApprovals.ApproveMergeRequest(int(repo.Id), int64(mergeRequest.Number), &gitlab.ApproveMergeRequestOptions{})
 		if err != nil {
 			return fmt.Errorf("failed to approve merge request: %w", err)
 		}
 	} else {
-		_, err := n.client.MergeRequestApprovals.UnapproveMergeRequest(int(repo.Id), mergeRequest.Id)
+		_, err := n.client.MergeRequestApprovals.UnapproveMergeRequest(int(repo.Id), int64(mergeRequest.Number))
 		if err != nil {
 			return fmt.Errorf("failed to unapprove merge request: %w", err)
 		}
@@ -222,13 +533,248 @@ func (n Platform) SubmitReview(repo api.Repository, mergeRequest api.MergeReques
 	return nil
 }
 
+func (n Platform) ApprovalState(repo api.Repository, mergeRequest api.MergeRequest) (api.ApprovalState, error) {
+	var result api.ApprovalState
+	mergeRequestIID := int64(mergeRequest.Number)
+
+	mr, _, err := n.client.MergeRequests.GetMergeRequest(int(repo.Id), mergeRequestIID, nil)
+	if err != nil {
+		return result, fmt.Errorf("failed to get merge request: %w", err)
+	}
+
+	approvals, _, err := n.client.MergeRequestApprovals.GetConfiguration(int(repo.Id), mergeRequestIID)
+	if err != nil {
+		return result, fmt.Errorf("failed to get merge request approvals: %w", err)
+	}
+	result.IsApproved = approvals.Approved
+	result.RequiredApprovals = int(approvals.ApprovalsRequired)
+	result.ApprovalsLeft = int(approvals.ApprovalsLeft)
+	for _, approver := range approvals.ApprovedBy {
+		result.ApprovedBy = append(result.ApprovedBy, toUser(approver.User))
+	}
+
+	// reviewers that approved are no longer pending
+	for _, reviewer := range toUsers(mr.Reviewers) {
+		if !slices.ContainsFunc(result.ApprovedBy, func(u api.User) bool { return u.ID == reviewer.ID }) {
+			result.RequestedReviewers = append(result.RequestedReviewers, reviewer)
+		}
+	}
+
+	// approval rules are only available in GitLab Premium
+	state, _, err := n.client.MergeRequestApprovals.GetApprovalState(int(repo.Id), mergeRequestIID)
+	if err != nil && !errors.Is(err, gitlab.ErrNotFound) {
+		return result, fmt.Errorf("failed to get merge request approval state: %w", err)
+	}
+	if state != nil {
+		for _, rule := range state.Rules {
+			r := api.ApprovalRule{
+				Name:              rule.Name,
+				Type:              toApprovalRuleType(rule.RuleType),
+				RequiredApprovals: int(rule.ApprovalsRequired),
+				ApprovedBy:        toUsers(rule.ApprovedBy),
+				EligibleApprovers: toUsers(rule.EligibleApprovers),
+				IsApproved:        rule.Approved,
+			}
+			if r.Type == api.ApprovalRuleTypeCodeOwner && r.RequiredApprovals > 0 {
+				result.RequiresCodeOwnerApproval = true
+			}
+			result.Rules = append(result.Rules, r)
+		}
+	}
+
+	return result, nil
+}
+
+func (n Platform) CreateReview(repo api.Repository, mergeRequest api.MergeRequest, review api.Review) error {
+	mergeRequestIID := int64(mergeRequest.Number)
+	if !slices.Contains([]api.ReviewEvent{api.ReviewEventApprove, api.ReviewEventRequestChanges, api.ReviewEventComment, ""}, review.Event) {
+		return fmt.Errorf("unsupported review event: %s", review.Event)
+	}
+
+	// collect the body and inline comments as draft notes, publishing them at once creates a single review
+	if review.Body != "" {
+		_, _, err := n.client.DraftNotes.CreateDraftNote(int(repo.Id), mergeRequestIID, &gitlab.CreateDraftNoteOptions{
+			Note: ptr.Ptr(review.Body),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create draft note: %w", err)
+		}
+	}
+
+	if len(review.Comments) > 0 {
+		mr, _, err := n.client.MergeRequests.GetMergeRequest(int(repo.Id), mergeRequestIID, nil)
+		if err != nil {
+			return fmt.Errorf("failed to get merge request: %w", err)
+		}
+
+		for _, c := range review.Comments {
+			oldPath := c.OldPath
+			if oldPath == "" {
+				oldPath = c.Path
+			}
+			position := &gitlab.PositionOptions{
+				BaseSHA:      ptr.Ptr(mr.DiffRefs.BaseSha),
+				StartSHA:     ptr.Ptr(mr.DiffRefs.StartSha),
+				HeadSHA:      ptr.Ptr(mr.DiffRefs.HeadSha),
+				PositionType: ptr.Ptr("text"),
+				NewPath:      ptr.Ptr(c.Path),
+				OldPath:      ptr.Ptr(oldPath),
+			}
+			if c.NewLine > 0 {
+				position.NewLine = ptr.Ptr(int64(c.NewLine))
+			}
+			if c.OldLine > 0 {
+				position.OldLine = ptr.Ptr(int64(c.OldLine))
+			}
+
+			_, _, err = n.client.DraftNotes.CreateDraftNote(int(repo.Id), mergeRequestIID, &gitlab.CreateDraftNoteOptions{
+				Note:     ptr.Ptr(c.Body),
+				CommitID: ptr.Ptr(mr.DiffRefs.HeadSha),
+				Position: position,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create review comment on %s (new line %d, old line %d): %w", c.Path, c.NewLine, c.OldLine, err)
+			}
+		}
+	}
+
+	if review.Body != "" || len(review.Comments) > 0 {
+		_, err := n.client.DraftNotes.PublishAllDraftNotes(int(repo.Id), mergeRequestIID)
+		if err != nil {
+			return fmt.Errorf("failed to publish draft notes: %w", err)
+		}
+	}
+
+	switch review.Event {
+	case api.ReviewEventApprove:
+		_, _, err := n.client.MergeRequestApprovals.ApproveMergeRequest(int(repo.Id), mergeRequestIID, &gitlab.ApproveMergeRequestOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to approve merge request: %w", err)
+		}
+	case api.ReviewEventRequestChanges:
+		// GitLab has no request changes state, revoke an existing approval of the current user instead
+		approvals, _, err := n.client.MergeRequestApprovals.GetConfiguration(int(repo.Id), mergeRequestIID)
+		if err != nil {
+			return fmt.Errorf("failed to get merge request approvals: %w", err)
+		}
+		if approvals.UserHasApproved {
+			_, err = n.client.MergeRequestApprovals.UnapproveMergeRequest(int(repo.Id), mergeRequestIID)
+			if err != nil {
+				return fmt.Errorf("failed to unapprove merge request: %w", err)
+			}
+		}
+	}
+
+	return nil
+}
+
 func (n Platform) Merge(repo api.Repository, mergeRequest api.MergeRequest, mergeStrategy api.MergeStrategyOptions) error {
-	_, _, err := n.client.MergeRequests.AcceptMergeRequest(int(repo.Id), mergeRequest.Id, &gitlab.AcceptMergeRequestOptions{
+	opts, err := n.acceptMergeRequestOptions(repo, mergeStrategy)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = n.client.MergeRequests.AcceptMergeRequest(int(repo.Id), int64(mergeRequest.Number), opts)
+	if err != nil {
+		return fmt.Errorf("failed to resolve merge request: %w", err)
+	}
+
+	return nil
+}
+
+func (n Platform) EnableAutoMerge(repo api.Repository, mergeRequest api.MergeRequest, mergeStrategy api.MergeStrategyOptions) error {
+	_, err := n.enableAutoMerge(repo, int64(mergeRequest.Number), mergeStrategy)
+	return err
+}
+
+func (n Platform) enableAutoMerge(repo api.Repository, mergeRequestIID int64, mergeStrategy api.MergeStrategyOptions) (*gitlab.MergeRequest, error) {
+	opts, err := n.acceptMergeRequestOptions(repo, mergeStrategy)
+	if err != nil {
+		return nil, err
+	}
+	opts.AutoMerge = ptr.True()
+	opts.MergeWhenPipelineSucceeds = ptr.True() // older GitLab versions only support merge_when_pipeline_succeeds
+
+	mr, _, err := n.client.MergeRequests.AcceptMergeRequest(int(repo.Id), mergeRequestIID, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to enable auto-merge for merge request: %w", err)
+	}
+
+	return mr, nil
+}
+
+// acceptMergeRequestOptions converts the merge strategy into accept options, the merge method is a project setting on GitLab and can only be validated
+func (n Platform) acceptMergeRequestOptions(repo api.Repository, mergeStrategy api.MergeStrategyOptions) (*gitlab.AcceptMergeRequestOptions, error) {
+	opts := &gitlab.AcceptMergeRequestOptions{
 		Squash:                   mergeStrategy.Squash,
 		ShouldRemoveSourceBranch: mergeStrategy.RemoveSourceBranch,
+	}
+	if mergeStrategy.ExpectedHeadSHA != "" {
+		opts.SHA = ptr.Ptr(mergeStrategy.ExpectedHeadSHA)
+	}
+
+	if method := api.ResolveMergeMethod(mergeStrategy); method != "" {
+		project, _, err := n.client.Projects.GetProject(int(repo.Id), nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get project: %w", err)
+		}
+		err = checkMergeMethod(project, method)
+		if err != nil {
+			return nil, err
+		}
+		opts.Squash = ptr.Ptr(method == api.MergeMethodSquash)
+	}
+
+	if message := commitMessage(mergeStrategy.CommitTitle, mergeStrategy.CommitMessage); message != "" {
+		if ptr.ValueOrDefault(opts.Squash, false) {
+			opts.SquashCommitMessage = ptr.Ptr(message)
+		} else {
+			opts.MergeCommitMessage = ptr.Ptr(message)
+		}
+	}
+
+	return opts, nil
+}
+
+func (n Platform) CloseMergeRequest(repo api.Repository, mergeRequest api.MergeRequest, message *string) error {
+	return n.closeMergeRequest(repo, int64(mergeRequest.Number), message)
+}
+
+func (n Platform) closeMergeRequest(repo api.Repository, mergeRequestIID int64, message *string) error {
+	if message != nil {
+		_, _, err := n.client.Notes.CreateMergeRequestNote(int(repo.Id), mergeRequestIID, &gitlab.CreateMergeRequestNoteOptions{
+			Body: message,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create note: %w", err)
+		}
+	}
+
+	_, _, err := n.client.MergeRequests.UpdateMergeRequest(int(repo.Id), mergeRequestIID, &gitlab.UpdateMergeRequestOptions{
+		StateEvent: ptr.Ptr("close"),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to resolve merge request: %w", err)
+		return fmt.Errorf("failed to close merge request: %w", err)
+	}
+
+	return nil
+}
+
+func (n Platform) ReopenMergeRequest(repo api.Repository, mergeRequest api.MergeRequest) error {
+	_, _, err := n.client.MergeRequests.UpdateMergeRequest(int(repo.Id), int64(mergeRequest.Number), &gitlab.UpdateMergeRequestOptions{
+		StateEvent: ptr.Ptr("reopen"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reopen merge request: %w", err)
+	}
+
+	return nil
+}
+
+func (n Platform) DeleteBranch(repo api.Repository, branch string) error {
+	_, err := n.client.Branches.DeleteBranch(int(repo.Id), branch)
+	if err != nil {
+		return fmt.Errorf("failed to delete branch %s: %w", branch, err)
 	}
 
 	return nil
@@ -249,6 +795,15 @@ func (n Platform) Languages(repo api.Repository) (map[string]int, error) {
 }
 
 func (n Platform) CommitAndPush(repo api.Repository, base string, branch string, message string, dir string) error {
+	return n.PushCommits(repo, base, branch, []api.Commit{{Message: message}}, dir)
+}
+
+func (n Platform) PushCommits(repo api.Repository, base string, branch string, commits []api.Commit, dir string) error {
+	if n.commitsAPI {
+		return n.pushCommitsAPI(repo, base, branch, commits, dir)
+	}
+	commits = api.SignOffCommits(commits, n.author)
+
 	// open repo
 	r, err := git.PlainOpen(dir)
 	if err != nil {
@@ -258,23 +813,51 @@ func (n Platform) CommitAndPush(repo api.Repository, base string, branch string,
 	if err != nil {
 		return fmt.Errorf("failed to get worktree: %w", err)
 	}
-
-	// track files and create commit
-	err = w.AddWithOptions(&git.AddOptions{
-		All: true,
-	})
+	status, err := w.Status()
 	if err != nil {
-		return fmt.Errorf("failed to add files: %w", err)
+		return fmt.Errorf("failed to get status: %w", err)
 	}
-	_, err = w.Commit(message, &git.CommitOptions{
-		Author: &object.Signature{
-			Name:  n.author.Name,
-			Email: n.author.Email,
-			When:  time.Now(),
-		},
-	})
+	lineEndings, err := api.NewLineEndingPolicy(dir)
 	if err != nil {
-		return fmt.Errorf("failed to create commit: %w", err)
+		return err
+	}
+	var changedFiles []string
+	for file := range status {
+		changedFiles = append(changedFiles, file)
+	}
+
+	// track files and create commits
+	created := 0
+	for i, files := range api.AssignCommitPaths(changedFiles, commits) {
+		if len(files) == 0 {
+			log.Debug().Str("message", commits[i].Message).Msg("no changes for commit, skipping")
+			continue
+		}
+
+		for _, file := range files {
+			// go-git stages the worktree content as is, normalize the line endings in place so the commit matches the api based backends
+			if err = normalizeLineEndings(dir, file, lineEndings); err != nil {
+				return err
+			}
+			if _, err = w.Add(file); err != nil {
+				return fmt.Errorf("failed to add file %s: %w", file, err)
+			}
+		}
+		_, err = w.Commit(api.CommitMessage(commits[i]), &git.CommitOptions{
+			Author: &object.Signature{
+				Name:  n.author.Name,
+				Email: n.author.Email,
+				When:  time.Now(),
+			},
+			Signer: n.signer,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create commit: %w", err)
+		}
+		created++
+	}
+	if created == 0 {
+		return fmt.Errorf("no changes to commit")
 	}
 
 	// push changes
@@ -290,63 +873,237 @@ func (n Platform) CommitAndPush(repo api.Repository, base string, branch string,
 	return nil
 }
 
-func (n Platform) CreateMergeRequest(repository api.Repository, sourceBranch string, title string, description string) error {
-	_, _, err := n.client.MergeRequests.CreateMergeRequest(int(repository.Id), &gitlab.CreateMergeRequestOptions{
-		Title:              ptr.Ptr(title),
-		Description:        ptr.Ptr(description),
-		SourceBranch:       ptr.Ptr(sourceBranch),
-		TargetBranch:       ptr.Ptr(repository.DefaultBranch),
-		RemoveSourceBranch: ptr.True(),
-		Squash:             ptr.True(),
-	})
+func (n Platform) CreateMergeRequest(repository api.Repository, sourceBranch string, title string, description string, options api.MergeRequestOptions) (api.MergeRequest, error) {
+	mr, err := n.createMergeRequest(repository, sourceBranch, title, description, options)
 	if err != nil {
-		return fmt.Errorf("failed to create merge request: %w", err)
+		return api.MergeRequest{}, err
 	}
 
-	return nil
+	return toMergeRequest(&mr.BasicMergeRequest, repository), nil
 }
 
-func (n Platform) CreateOrUpdateMergeRequest(repository api.Repository, sourceBranch string, title string, description string, key string) error {
-	description = fmt.Sprintf("%s\n\n<!--vcs-merge-request-key:%s-->", description, key)
+func (n Platform) CreateOrUpdateMergeRequest(repository api.Repository, sourceBranch string, title string, description string, key string, options api.MergeRequestOptions) (api.MergeRequest, api.MergeRequestOutcome, error) {
+	description = fmt.Sprintf("%s\n\n%s", description, api.MergeRequestKeyMarker(key))
+	targetBranch := targetBranch(repository, options)
 
-	// Search for an existing merge request with the same source branch
-	mrs, _, err := n.client.MergeRequests.ListProjectMergeRequests(int(repository.Id), &gitlab.ListProjectMergeRequestsOptions{
-		SourceBranch: ptr.Ptr(sourceBranch),
-		TargetBranch: ptr.Ptr(repository.DefaultBranch),
-		State:        ptr.Ptr("opened"),
-	})
-	if err != nil {
-		return fmt.Errorf("failed to list merge requests: %w", err)
-	}
+	// search merge request by key
 	var existingMR *gitlab.BasicMergeRequest
-	for _, mr := range mrs {
-		existingMR = mr
-		break
+	var supersededMR *gitlab.BasicMergeRequest
+	keyedMR, err := n.findMergeRequestByKey(repository, key, true)
+	if err != nil && !errors.Is(err, api.ErrNotFound) {
+		return api.MergeRequest{}, "", err
+	}
+	if keyedMR != nil && keyedMR.SourceBranch == sourceBranch {
+		existingMR = keyedMR
+	} else if keyedMR != nil {
+		supersededMR = keyedMR
 	}
 
-	if existingMR != nil {
-		_, _, updateErr := n.client.MergeRequests.UpdateMergeRequest(int(repository.Id), existingMR.IID, &gitlab.UpdateMergeRequestOptions{
-			Title:       &title,
-			Description: &description,
+	// Search for an existing merge request with the same source branch, for merge requests created without a key
+	if existingMR == nil {
+		mrs, _, err := n.client.MergeRequests.ListProjectMergeRequests(int(repository.Id), &gitlab.ListProjectMergeRequestsOptions{
+			SourceBranch: ptr.Ptr(sourceBranch),
+			TargetBranch: ptr.Ptr(targetBranch),
+			State:        ptr.Ptr("opened"),
 		})
-		if updateErr != nil {
-			return fmt.Errorf("failed to update merge request: %w", updateErr)
+		if err != nil {
+			return api.MergeRequest{}, "", fmt.Errorf("failed to list merge requests: %w", err)
+		}
+		for _, mr := range mrs {
+			existingMR = mr
+			break
+		}
+	}
+
+	var result api.MergeRequest
+	var outcome api.MergeRequestOutcome
+	if existingMR != nil {
+		if api.IsMergeRequestUpToDate(toMergeRequest(existingMR, repository), draftTitle(title, options.IsDraft), description, options) {
+			result, outcome = toMergeRequest(existingMR, repository), api.MergeRequestOutcomeUnchanged
+		} else {
+			mr, updateErr := n.updateMergeRequest(repository, existingMR.IID, title, description, options)
+			if mr == nil {
+				return api.MergeRequest{}, "", updateErr
+			}
+			result, outcome = toMergeRequest(&mr.BasicMergeRequest, repository), api.MergeRequestOutcomeUpdated
+			if updateErr != nil {
+				return result, outcome, updateErr
+			}
 		}
 	} else {
-		_, _, createErr := n.client.MergeRequests.CreateMergeRequest(int(repository.Id), &gitlab.CreateMergeRequestOptions{
-			Title:              ptr.Ptr(title),
-			Description:        ptr.Ptr(description),
-			SourceBranch:       ptr.Ptr(sourceBranch),
-			TargetBranch:       ptr.Ptr(repository.DefaultBranch),
-			RemoveSourceBranch: ptr.True(),
-			Squash:             ptr.True(),
-		})
+		mr, createErr := n.createMergeRequest(repository, sourceBranch, title, description, options)
+		if mr == nil {
+			return api.MergeRequest{}, "", createErr
+		}
+		result, outcome = toMergeRequest(&mr.BasicMergeRequest, repository), api.MergeRequestOutcomeCreated
 		if createErr != nil {
-			return fmt.Errorf("failed to create merge request: %w", createErr)
+			return result, outcome, createErr
 		}
 	}
 
-	return nil
+	// close the merge request that was replaced, also if the current one was found by source branch
+	if supersededMR != nil && int(supersededMR.IID) != result.Number {
+		log.Debug().Int64("iid", supersededMR.IID).Str("source-branch", supersededMR.SourceBranch).Int("superseded-by", result.Number).Msg("closing merge request with the same key")
+		err = n.closeMergeRequest(repository, supersededMR.IID, ptr.Ptr(fmt.Sprintf("Superseded by !%d.", result.Number)))
+		if err != nil {
+			return result, outcome, err
+		}
+	}
+
+	return result, outcome, nil
+}
+
+func (n Platform) createMergeRequest(repository api.Repository, sourceBranch string, title string, description string, options api.MergeRequestOptions) (*gitlab.MergeRequest, error) {
+	assigneeIds, err := n.userIds(options.Assignees)
+	if err != nil {
+		return nil, err
+	}
+	reviewerIds, err := n.userIds(options.Reviewers)
+	if err != nil {
+		return nil, err
+	}
+	milestoneId, err := n.milestoneId(repository, options.Milestone)
+	if err != nil {
+		return nil, err
+	}
+
+	createOpts := &gitlab.CreateMergeRequestOptions{
+		Title:              ptr.Ptr(draftTitle(title, options.IsDraft)),
+		Description:        ptr.Ptr(description),
+		SourceBranch:       ptr.Ptr(sourceBranch),
+		TargetBranch:       ptr.Ptr(targetBranch(repository, options)),
+		AssigneeIDs:        assigneeIds,
+		ReviewerIDs:        reviewerIds,
+		MilestoneID:        milestoneId,
+		RemoveSourceBranch: valueOrTrue(options.RemoveSourceBranch),
+		Squash:             valueOrTrue(options.Squash),
+		AllowCollaboration: options.AllowMaintainerEdit,
+	}
+	if options.Labels != nil {
+		createOpts.Labels = ptr.Ptr(gitlab.LabelOptions(options.Labels))
+	}
+
+	mr, _, err := n.client.MergeRequests.CreateMergeRequest(int(repository.Id), createOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create merge request: %w", err)
+	}
+
+	return n.applyAutoMerge(repository, mr, options)
+}
+
+func (n Platform) updateMergeRequest(repository api.Repository, mergeRequestIID int64, title string, description string, options api.MergeRequestOptions) (*gitlab.MergeRequest, error) {
+	assigneeIds, err := n.userIds(options.Assignees)
+	if err != nil {
+		return nil, err
+	}
+	reviewerIds, err := n.userIds(options.Reviewers)
+	if err != nil {
+		return nil, err
+	}
+	milestoneId, err := n.milestoneId(repository, options.Milestone)
+	if err != nil {
+		return nil, err
+	}
+
+	updateOpts := &gitlab.UpdateMergeRequestOptions{
+		Title:              ptr.Ptr(draftTitle(title, options.IsDraft)),
+		Description:        ptr.Ptr(description),
+		TargetBranch:       ptr.Ptr(targetBranch(repository, options)),
+		AssigneeIDs:        assigneeIds,
+		ReviewerIDs:        reviewerIds,
+		MilestoneID:        milestoneId,
+		RemoveSourceBranch: valueOrTrue(options.RemoveSourceBranch),
+		Squash:             valueOrTrue(options.Squash),
+		AllowCollaboration: options.AllowMaintainerEdit,
+	}
+	if options.Labels != nil {
+		updateOpts.Labels = ptr.Ptr(gitlab.LabelOptions(options.Labels))
+	}
+
+	mr, _, err := n.client.MergeRequests.UpdateMergeRequest(int(repository.Id), mergeRequestIID, updateOpts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update merge request: %w", err)
+	}
+
+	return n.applyAutoMerge(repository, mr, options)
+}
+
+const (
+	headPipelineTimeout      = 2 * time.Minute
+	headPipelinePollInterval = 5 * time.Second
+)
+
+// applyAutoMerge enables auto-merge if requested in the options, once the head pipeline of the merge request exists.
+// GitLab merges immediately if auto-merge is enabled before the pipeline was created, so it polls the merge request until the pipeline exists
+// and returns api.ErrPipelineTimeout if it does not appear in time.
+func (n Platform) applyAutoMerge(repository api.Repository, mr *gitlab.MergeRequest, options api.MergeRequestOptions) (*gitlab.MergeRequest, error) {
+	if options.AutoMerge == nil || options.IsDraft || mr.MergeWhenPipelineSucceeds {
+		return mr, nil
+	}
+
+	// the pipeline is created asynchronously after the push, wait until it exists for the head commit
+	deadline := time.Now().Add(headPipelineTimeout)
+	for mr.HeadPipeline == nil || mr.HeadPipeline.SHA != mr.SHA {
+		if time.Now().After(deadline) {
+			return mr, fmt.Errorf("failed to enable auto-merge for merge request !%d, no pipeline for head commit %s after %s: %w", mr.IID, mr.SHA, headPipelineTimeout, api.ErrPipelineTimeout)
+		}
+		log.Debug().Int64("iid", mr.IID).Str("sha", mr.SHA).Msg("waiting for the pipeline of the head commit")
+		time.Sleep(headPipelinePollInterval)
+
+		latest, _, err := n.client.MergeRequests.GetMergeRequest(int(repository.Id), mr.IID, nil)
+		if err != nil {
+			return mr, fmt.Errorf("failed to get merge request: %w", err)
+		}
+		mr = latest
+	}
+
+	updated, err := n.enableAutoMerge(repository, mr.IID, *options.AutoMerge)
+	if err != nil {
+		return mr, err
+	}
+
+	return updated, nil
+}
+
+// userIds resolves usernames to user ids
+func (n Platform) userIds(usernames []string) (*[]int64, error) {
+	if usernames == nil {
+		return nil, nil
+	}
+
+	result := make([]int64, 0, len(usernames))
+	for _, username := range usernames {
+		users, _, err := n.client.Users.ListUsers(&gitlab.ListUsersOptions{Username: ptr.Ptr(username)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query user %s: %w", username, err)
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("user %s not found", username)
+		}
+		result = append(result, users[0].ID)
+	}
+
+	return &result, nil
+}
+
+// milestoneId resolves a milestone title to the milestone id, including milestones of parent groups
+func (n Platform) milestoneId(repository api.Repository, title string) (*int64, error) {
+	if title == "" {
+		return nil, nil
+	}
+
+	milestones, _, err := n.client.Milestones.ListMilestones(int(repository.Id), &gitlab.ListMilestonesOptions{
+		Title:            ptr.Ptr(title),
+		IncludeAncestors: ptr.True(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list milestones: %w", err)
+	}
+	if len(milestones) == 0 {
+		return nil, fmt.Errorf("milestone %s not found in repository %s", title, repository.Path)
+	}
+
+	return ptr.Ptr(milestones[0].ID), nil
 }
 
 func (n Platform) AuthMethod(repo api.Repository) githttp.AuthMethod {
@@ -356,27 +1113,121 @@ func (n Platform) AuthMethod(repo api.Repository) githttp.AuthMethod {
 	}
 }
 
+func (n Platform) ListFiles(repository api.Repository, ref string, path string, recursive bool) ([]api.TreeEntry, error) {
+	var result []api.TreeEntry
+	if ref == "" {
+		ref = repository.DefaultBranch
+	}
+
+	var nodes []*gitlab.TreeNode
+	opts := &gitlab.ListTreeOptions{
+		Path:      gitlab.Ptr(path),
+		Ref:       gitlab.Ptr(ref),
+		Recursive: gitlab.Ptr(recursive),
+		ListOptions: gitlab.ListOptions{
+			PerPage: pageSize,
+		},
+	}
+	for {
+		data, resp, err := n.client.Repositories.ListTree(int(repository.Id), opts)
+		if err != nil {
+			if errors.Is(err, gitlab.ErrNotFound) {
+				return result, fmt.Errorf("directory %s not found in %s: %w", path, ref, api.ErrNotFound)
+			}
+			return result, fmt.Errorf("failed to list files: %w", err)
+		}
+		nodes = append(nodes, data...)
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
+	}
+	// git has no empty directories, older GitLab versions return an empty list instead of 404 for missing paths
+	if len(nodes) == 0 && path != "" {
+		return result, fmt.Errorf("directory %s not found in %s: %w", path, ref, api.ErrNotFound)
+	}
+
+	for _, node := range nodes {
+		result = append(result, api.TreeEntry{
+			Path: node.Path,
+			Name: node.Name,
+			Type: api.TreeEntryType(node.Type),
+			Mode: node.Mode,
+			SHA:  node.ID,
+		})
+	}
+
+	return result, nil
+}
+
 func (n Platform) FileContent(repository api.Repository, branch string, path string) (string, error) {
-	// query file
-	file, _, err := n.client.RepositoryFiles.GetFile(int(repository.Id), path, &gitlab.GetFileOptions{
-		Ref: gitlab.Ptr(branch),
-	})
+	file, err := n.File(repository, branch, path)
 	if err != nil {
-		return "", fmt.Errorf("failed to get file: %w", err)
+		return "", err
 	}
 
-	if file.Encoding == "base64" {
-		decoded, err := base64.StdEncoding.DecodeString(file.Content)
-		if err != nil {
-			return "", fmt.Errorf("failed to decode file %s with encoding %s: %w", path, file.Encoding, err)
+	return string(file.Content), nil
+}
+
+func (n Platform) File(repository api.Repository, ref string, filePath string) (api.File, error) {
+	filePath = strings.Trim(filePath, "/")
+	result := api.File{Path: filePath}
+
+	// query metadata from the parent tree, to get the mode of symlinks and submodules
+	dir := path.Dir(filePath)
+	if dir == "." {
+		dir = ""
+	}
+	entries, err := n.ListFiles(repository, ref, dir, false)
+	if err != nil {
+		return result, err
+	}
+
+	var entry *api.TreeEntry
+	for i := range entries {
+		if entries[i].Path == filePath {
+			entry = &entries[i]
+			break
 		}
+	}
+	if entry == nil || entry.Type == api.TreeEntryTypeTree {
+		return result, fmt.Errorf("file %s not found: %w", filePath, api.ErrNotFound)
+	}
+	result.SHA = entry.SHA
+	result.Mode = entry.Mode
 
-		return string(decoded), nil
-	} else if file.Encoding == "text" {
-		return file.Content, nil
+	// submodules do not have a blob
+	if entry.Type == api.TreeEntryTypeCommit {
+		return result, nil
+	}
+
+	// query raw content, supports binary and large files
+	content, _, err := n.client.Repositories.RawBlobContent(int(repository.Id), entry.SHA)
+	if err != nil {
+		return result, fmt.Errorf("failed to get blob %s: %w", entry.SHA, err)
 	}
+	result.Content = content
+	result.Size = int64(len(content))
 
-	return "", fmt.Errorf("unknown encoding %s for file %s", file.Encoding, path)
+	return result, nil
+}
+
+func (n Platform) FileExists(repository api.Repository, ref string, path string) (bool, error) {
+	if ref == "" {
+		ref = repository.DefaultBranch
+	}
+
+	_, _, err := n.client.RepositoryFiles.GetFileMetaData(int(repository.Id), path, &gitlab.GetFileMetaDataOptions{
+		Ref: gitlab.Ptr(ref),
+	})
+	if err != nil {
+		if errors.Is(err, gitlab.ErrNotFound) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to get file metadata: %w", err)
+	}
+
+	return true, nil
 }
 
 func (n Platform) Tags(repository api.Repository, limit int) ([]api.Tag, error) {
@@ -528,10 +1379,19 @@ func NewPlatform(config Config) (Platform, error) {
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to create gitlab client")
 	}
+	if config.CommitsAPI && config.Signing.Key != "" {
+		return Platform{}, fmt.Errorf("commit signing is not supported with the commits api, the commits are created by GitLab and can not be signed locally")
+	}
+	signer, err := api.NewCommitSigner(config.Signing)
+	if err != nil {
+		return Platform{}, fmt.Errorf("failed to create commit signer: %w", err)
+	}
 
 	return Platform{
 		accessToken: config.AccessToken,
 		author:      config.Author,
+		signer:      signer,
+		commitsAPI:  config.CommitsAPI,
 		client:      client,
 	}, nil
 }
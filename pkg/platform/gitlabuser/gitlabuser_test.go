@@ -0,0 +1,79 @@
+package gitlabuser
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+)
+
+func TestNewPlatformSigningWithCommitsAPI(t *testing.T) {
+	_, err := NewPlatform(Config{
+		Server:     "https://gitlab.com",
+		CommitsAPI: true,
+		Signing:    api.SigningConfig{Format: api.SigningFormatSSH, Key: "key"},
+	})
+	if err == nil {
+		t.Errorf("expected an error for signing with the commits api, but got nil")
+	}
+}
+
+func TestToPipelineState(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected api.PipelineState
+	}{
+		{"waiting_for_resource", api.PipelineStateWaitingForResource},
+		{"preparing", api.PipelineStatePreparing},
+		{"waiting_for_callback", api.PipelineStatePending},
+		{"canceling", api.PipelineStateRunning},
+		{"failed", api.PipelineStateFailed},
+		{"something_new", api.PipelineStateUnknown},
+	}
+
+	for _, tc := range testCases {
+		result := toPipelineState(tc.input)
+		if result != tc.expected {
+			t.Errorf("For case %s, expected %s, but got %s", tc.input, tc.expected, result)
+		}
+	}
+}
+
+func TestFindRepositoryNumericPath(t *testing.T) {
+	var requests []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v4/projects/{id}", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.PathValue("id"))
+		if r.PathValue("id") != "0042" {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = fmt.Fprint(w, `{"message":"404 Project Not Found"}`)
+			return
+		}
+		_, _ = fmt.Fprint(w, `{"id":7,"path_with_namespace":"group/0042","namespace":{"full_path":"group"},"path":"0042"}`)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	platform, err := NewPlatform(Config{Server: server.URL})
+	if err != nil {
+		t.Fatalf("failed to create platform: %v", err)
+	}
+
+	repo, err := platform.FindRepository("0042")
+	if err != nil || repo.Id != 7 {
+		t.Errorf("expected project 7, but got %+v and %v", repo, err)
+	}
+	if !slices.Equal(requests, []string{"42", "0042"}) {
+		t.Errorf("expected id lookup followed by path lookup, but got %v", requests)
+	}
+
+	requests = nil
+	_, err = platform.FindRepository("13")
+	if !errors.Is(err, api.ErrNotFound) || len(requests) != 1 {
+		t.Errorf("expected not found after a single id lookup, but got %v after %v", err, requests)
+	}
+}
@@ -1,6 +1,8 @@
 package gitlabuser
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/cidverse/go-ptr"
@@ -18,6 +20,108 @@ func branchSliceToNameSlice(branches []*gitlab.Branch) []string {
 	return branchNames
 }
 
+func targetBranch(repo api.Repository, options api.MergeRequestOptions) string {
+	if options.TargetBranch != "" {
+		return options.TargetBranch
+	}
+
+	return repo.DefaultBranch
+}
+
+// draftTitle adds or removes the draft prefix, GitLab derives the draft state from the title
+func draftTitle(title string, draft bool) string {
+	title = strings.TrimSpace(title)
+	for _, prefix := range []string{"Draft:", "[Draft]", "(Draft)"} {
+		if len(title) >= len(prefix) && strings.EqualFold(title[:len(prefix)], prefix) {
+			title = strings.TrimSpace(title[len(prefix):])
+		}
+	}
+
+	if draft {
+		return "Draft: " + title
+	}
+	return title
+}
+
+func toRepositoryVisibility(visibility gitlab.VisibilityValue) api.RepositoryVisibility {
+	switch visibility {
+	case gitlab.PublicVisibility:
+		return api.RepositoryVisibilityPublic
+	case gitlab.InternalVisibility:
+		return api.RepositoryVisibilityInternal
+	default:
+		return api.RepositoryVisibilityPrivate
+	}
+}
+
+// primaryLanguage returns the language with the highest share
+func primaryLanguage(languages map[string]float32) string {
+	var result string
+	var share float32
+	for language, percentage := range languages {
+		if percentage > share || (percentage == share && language < result) {
+			result = language
+			share = percentage
+		}
+	}
+
+	return result
+}
+
+func toBranch(branch *gitlab.Branch) api.Branch {
+	result := api.Branch{
+		Name:        branch.Name,
+		IsDefault:   branch.Default,
+		IsProtected: branch.Protected,
+	}
+	if branch.Commit != nil {
+		result.CommitHash = branch.Commit.ID
+		result.CommitDate = branch.Commit.CommittedDate
+	}
+
+	return result
+}
+
+func toMergeRequest(mr *gitlab.BasicMergeRequest, repo api.Repository) api.MergeRequest {
+	result := api.MergeRequest{
+		Id:                 mr.ID,
+		Number:             int(mr.IID),
+		Title:              mr.Title,
+		Description:        mr.Description,
+		Labels:             toMergeRequestLabels(mr.Labels),
+		Assignees:          toUsers(mr.Assignees),
+		Reviewers:          toUsers(mr.Reviewers),
+		SourceBranch:       mr.SourceBranch,
+		TargetBranch:       mr.TargetBranch,
+		State:              toMergeRequestState(mr.State),
+		IsMerged:           mr.MergedAt != nil,
+		IsLocked:           mr.DiscussionLocked,
+		IsDraft:            mr.Draft,
+		HasConflicts:       mr.HasConflicts,
+		IsAutoMergeEnabled: mr.MergeWhenPipelineSucceeds,
+		CanMerge:           mr.DetailedMergeStatus == "mergeable", // see https://docs.gitlab.com/ee/api/merge_requests.html#merge-status
+		Author:             toUser(mr.Author),
+		WebURL:             mr.WebURL,
+		HeadSHA:            mr.SHA,
+		CreatedAt:          mr.CreatedAt,
+		UpdatedAt:          mr.UpdatedAt,
+		MergedAt:           mr.MergedAt,
+		Repository:         repo,
+	}
+	if mr.Milestone != nil {
+		result.Milestone = mr.Milestone.Title
+	}
+	result.Squash = ptr.Ptr(mr.Squash)
+	result.RemoveSourceBranch = ptr.Ptr(mr.ForceRemoveSourceBranch)
+	// maintainer edits only apply to merge requests from forks
+	if mr.SourceProjectID != mr.TargetProjectID {
+		result.AllowMaintainerEdit = ptr.Ptr(mr.AllowCollaboration)
+	}
+	result.PipelineState = api.PipelineStateUnknown // list request does not provide the head pipeline, see Platform.pipelineState
+
+	return result
+}
+
 func toMergeRequestLabels(labels gitlab.Labels) []string {
 	var result []string
 
@@ -44,9 +148,10 @@ func toPipelineState(state string) api.PipelineState {
 		return api.PipelineStateWaitingForResource
 	case "preparing":
 		return api.PipelineStatePreparing
-	case "pending":
+	case "pending", "waiting_for_callback":
 		return api.PipelineStatePending
-	case "running":
+	case "running", "canceling":
+		// canceling is still in progress, the final state is canceled
 		return api.PipelineStateRunning
 	case "success":
 		return api.PipelineStateSuccess
@@ -61,8 +166,35 @@ func toPipelineState(state string) api.PipelineState {
 	case "scheduled":
 		return api.PipelineStateScheduled
 	default:
-		return api.PipelineStateFailed
+		return api.PipelineStateUnknown
+	}
+}
+
+func toMergeRequestComment(note *gitlab.Note) api.MergeRequestComment {
+	return api.MergeRequestComment{
+		Id:   note.ID,
+		Body: note.Body,
+		Author: api.User{
+			ID:        note.Author.ID,
+			Username:  note.Author.Username,
+			Name:      note.Author.Name,
+			Type:      api.UserTypeUser,
+			State:     api.UserStateActive,
+			AvatarURL: note.Author.AvatarURL,
+		},
+		IsSystem:  note.System,
+		CreatedAt: note.CreatedAt,
+		UpdatedAt: note.UpdatedAt,
+	}
+}
+
+func toUsers(users []*gitlab.BasicUser) []api.User {
+	var result []api.User
+	for _, user := range users {
+		result = append(result, toUser(user))
 	}
+
+	return result
 }
 
 func toUser(user *gitlab.BasicUser) api.User {
@@ -89,3 +221,55 @@ func toUser(user *gitlab.BasicUser) api.User {
 		GlobalAdministrator: false,
 	}
 }
+
+func toApprovalRuleType(ruleType string) api.ApprovalRuleType {
+	switch ruleType {
+	case "code_owner":
+		return api.ApprovalRuleTypeCodeOwner
+	case "any_approver":
+		return api.ApprovalRuleTypeAnyApprover
+	case "report_approver":
+		return api.ApprovalRuleTypeReport
+	default:
+		return api.ApprovalRuleTypeRegular
+	}
+}
+
+// checkMergeMethod returns an error if the merge method is not allowed by the project settings
+func checkMergeMethod(project *gitlab.Project, method api.MergeMethod) error {
+	allowed := false
+	switch method {
+	case api.MergeMethodMerge:
+		allowed = project.MergeMethod != gitlab.FastForwardMerge && project.SquashOption != gitlab.SquashOptionAlways
+	case api.MergeMethodSquash:
+		allowed = project.SquashOption != gitlab.SquashOptionNever
+	case api.MergeMethodRebase:
+		allowed = project.MergeMethod == gitlab.RebaseMerge || project.MergeMethod == gitlab.FastForwardMerge
+	case api.MergeMethodFastForward:
+		allowed = project.MergeMethod == gitlab.FastForwardMerge
+	default:
+		return fmt.Errorf("unsupported merge method: %s", method)
+	}
+
+	if !allowed {
+		return fmt.Errorf("merge method %s is not allowed in project %s (merge method %s, squash option %s)", method, project.PathWithNamespace, project.MergeMethod, project.SquashOption)
+	}
+	return nil
+}
+
+// commitMessage joins the commit title and message, GitLab only accepts a single commit message
+func commitMessage(title string, message string) string {
+	if title == "" || message == "" {
+		return title + message
+	}
+
+	return title + "\n\n" + message
+}
+
+// valueOrTrue returns the value or true if it is not set, used for options that were always enabled before they became configurable
+func valueOrTrue(value *bool) *bool {
+	if value == nil {
+		return ptr.True()
+	}
+	return value
+}
@@ -1,18 +1,27 @@
 package simpletask
 
 import (
+	"errors"
 	"fmt"
+	"slices"
 
 	"github.com/cidverse/go-vcs"
 	"github.com/cidverse/go-vcs/vcsapi"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
 	"github.com/rs/zerolog/log"
 )
 
 type SimpleTask struct {
-	ctx        taskcommon.TaskContext
-	VCSClient  vcsapi.Client
-	BranchName string
+	ctx                 taskcommon.TaskContext
+	VCSClient           vcsapi.Client
+	BranchName          string
+	MergeRequestOptions api.MergeRequestOptions // options applied when creating or updating the merge request
+	CloseStale          bool                    // close the existing merge request and delete its branch if no changes remain
+	CloseStaleMessage   string                  // comment added when closing a stale merge request, a default message is used if empty
+	Commits             []api.Commit            // splits the changes into multiple commits, remaining changes are committed using the commit message
+	CommitTrailers      []api.CommitTrailer     // trailers appended to every commit, e.g. api.CoAuthoredBy
+	SignOff             bool                    // adds a Signed-off-by trailer for the commit author to every commit, for repositories that enforce the DCO
 }
 
 // Clone clones the repository and initializes the vcs client
@@ -43,39 +52,84 @@ func (n *SimpleTask) CreateBranch(branchName string) error {
 }
 
 // CommitPushAndMergeRequest commits and pushes the changes, additionally creates or updates the merge request
-func (n *SimpleTask) CommitPushAndMergeRequest(commitMessage string, mergeRequestTitle string, mergeRequestDescription string, mergeRequestKey string) error {
+func (n *SimpleTask) CommitPushAndMergeRequest(commitMessage string, mergeRequestTitle string, mergeRequestDescription string, mergeRequestKey string) (api.MergeRequest, api.MergeRequestOutcome, error) {
 	if n.VCSClient == nil {
-		return fmt.Errorf("vcs client is nil, call Clone first to initialize the vcs client")
+		return api.MergeRequest{}, "", fmt.Errorf("vcs client is nil, call Clone first to initialize the vcs client")
 	}
 	if n.BranchName == "" {
-		return fmt.Errorf("branch name is empty, call CreateBranch first")
+		return api.MergeRequest{}, "", fmt.Errorf("branch name is empty, call CreateBranch first")
 	}
 
 	// commit and push if changes are present
 	isClean, err := n.VCSClient.IsClean()
 	if err != nil {
-		return fmt.Errorf("failed to check if repository is clean: %w", err)
+		return api.MergeRequest{}, "", fmt.Errorf("failed to check if repository is clean: %w", err)
 	}
-	if !isClean {
-		head, err := n.VCSClient.VCSHead()
-		if err != nil {
-			return fmt.Errorf("failed to get head: %w", err)
-		}
-		err = n.ctx.Platform.CommitAndPush(n.ctx.Repository, head.Hash, n.BranchName, commitMessage, n.ctx.Directory)
-		if err != nil {
-			return fmt.Errorf("failed to commit and push: %w", err)
+	if isClean {
+		if n.CloseStale && mergeRequestKey != "" {
+			return n.closeStaleMergeRequest(mergeRequestKey)
 		}
-		log.Info().Str("branch", n.BranchName).Msg("pushed changes to remote")
+		return api.MergeRequest{}, api.MergeRequestOutcomeSkipped, nil
+	}
+
+	head, err := n.VCSClient.VCSHead()
+	if err != nil {
+		return api.MergeRequest{}, "", fmt.Errorf("failed to get head: %w", err)
+	}
+	commits := append(append([]api.Commit{}, n.Commits...), api.Commit{Message: commitMessage})
+	for i := range commits {
+		commits[i].Trailers = append(slices.Clone(commits[i].Trailers), n.CommitTrailers...)
+		commits[i].SignOff = commits[i].SignOff || n.SignOff
+	}
+	err = n.ctx.Platform.PushCommits(n.ctx.Repository, head.Hash, n.BranchName, commits, n.ctx.Directory)
+	if err != nil {
+		return api.MergeRequest{}, "", fmt.Errorf("failed to commit and push: %w", err)
+	}
+	log.Info().Str("branch", n.BranchName).Msg("pushed changes to remote")
+
+	// create or update merge request
+	mergeRequest, outcome, err := n.ctx.Platform.CreateOrUpdateMergeRequest(n.ctx.Repository, n.BranchName, mergeRequestTitle, mergeRequestDescription, mergeRequestKey, n.MergeRequestOptions)
+	if err != nil {
+		return api.MergeRequest{}, "", err
+	}
+	log.Info().Int("number", mergeRequest.Number).Str("url", mergeRequest.WebURL).Str("outcome", string(outcome)).Msg("created / updated merge request")
+
+	return mergeRequest, outcome, nil
+}
+
+// closeStaleMergeRequest closes the open merge request with the given key and deletes its source branch
+func (n *SimpleTask) closeStaleMergeRequest(mergeRequestKey string) (api.MergeRequest, api.MergeRequestOutcome, error) {
+	mergeRequest, err := n.ctx.Platform.FindMergeRequestByKey(n.ctx.Repository, mergeRequestKey, true)
+	if errors.Is(err, api.ErrNotFound) {
+		return api.MergeRequest{}, api.MergeRequestOutcomeSkipped, nil
+	} else if err != nil {
+		return api.MergeRequest{}, "", fmt.Errorf("failed to find merge request: %w", err)
+	}
+	// the key may match closed or merged merge requests, only open ones are stale
+	if mergeRequest.State != api.MergeRequestStateOpen || mergeRequest.IsMerged {
+		return api.MergeRequest{}, api.MergeRequestOutcomeSkipped, nil
+	}
+
+	message := n.CloseStaleMessage
+	if message == "" {
+		message = "Closing this merge request, the changes are no longer required."
+	}
+	err = n.ctx.Platform.CloseMergeRequest(n.ctx.Repository, mergeRequest, &message)
+	if err != nil {
+		return api.MergeRequest{}, "", err
+	}
+	mergeRequest.State = api.MergeRequestStateClosed
+	log.Info().Int("number", mergeRequest.Number).Str("url", mergeRequest.WebURL).Msg("closed stale merge request")
 
-		// create or update merge request
-		err = n.ctx.Platform.CreateOrUpdateMergeRequest(n.ctx.Repository, n.BranchName, mergeRequestTitle, mergeRequestDescription, mergeRequestKey)
+	if mergeRequest.SourceBranch != "" && mergeRequest.SourceBranch != n.ctx.Repository.DefaultBranch {
+		err = n.ctx.Platform.DeleteBranch(n.ctx.Repository, mergeRequest.SourceBranch)
 		if err != nil {
-			return err
+			return mergeRequest, "", err
 		}
-		log.Info().Msg("created / updated merge request")
+		log.Info().Str("branch", mergeRequest.SourceBranch).Msg("deleted branch of stale merge request")
 	}
 
-	return nil
+	return mergeRequest, api.MergeRequestOutcomeClosed, nil
 }
 
 // New creates a new instance of the basic task helper
@@ -0,0 +1,70 @@
+package simpletask
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
+)
+
+// fakePlatform returns the configured merge requests by key and records closed merge requests and deleted branches
+type fakePlatform struct {
+	api.Platform
+	mergeRequests   map[string]api.MergeRequest
+	openOnly        bool
+	closed          []int
+	deletedBranches []string
+}
+
+func (p *fakePlatform) FindMergeRequestByKey(repository api.Repository, key string, openOnly bool) (api.MergeRequest, error) {
+	p.openOnly = openOnly
+	if mr, ok := p.mergeRequests[key]; ok {
+		return mr, nil
+	}
+	return api.MergeRequest{}, fmt.Errorf("no merge request found for key %s: %w", key, api.ErrNotFound)
+}
+
+func (p *fakePlatform) CloseMergeRequest(repository api.Repository, mergeRequest api.MergeRequest, message *string) error {
+	p.closed = append(p.closed, mergeRequest.Number)
+	return nil
+}
+
+func (p *fakePlatform) DeleteBranch(repository api.Repository, branch string) error {
+	p.deletedBranches = append(p.deletedBranches, branch)
+	return nil
+}
+
+func TestCloseStaleMergeRequest(t *testing.T) {
+	testCases := []struct {
+		name            string
+		key             string
+		expectedOutcome api.MergeRequestOutcome
+		expectedClosed  int
+	}{
+		{"open merge request", "open", api.MergeRequestOutcomeClosed, 1},
+		{"key only matches closed merge request", "closed", api.MergeRequestOutcomeSkipped, 0},
+		{"key only matches merged merge request", "merged", api.MergeRequestOutcomeSkipped, 0},
+		{"no merge request", "unknown", api.MergeRequestOutcomeSkipped, 0},
+	}
+
+	for _, tc := range testCases {
+		platform := &fakePlatform{mergeRequests: map[string]api.MergeRequest{
+			"open":   {Number: 1, State: api.MergeRequestStateOpen, SourceBranch: "chore/open"},
+			"closed": {Number: 2, State: api.MergeRequestStateClosed, SourceBranch: "chore/closed"},
+			"merged": {Number: 3, State: api.MergeRequestStateClosed, IsMerged: true, SourceBranch: "chore/merged"},
+		}}
+		task := New(taskcommon.TaskContext{Platform: platform, Repository: api.Repository{DefaultBranch: "main"}})
+
+		_, outcome, err := task.closeStaleMergeRequest(tc.key)
+		if err != nil {
+			t.Fatalf("For case %s, expected no error, but got %v", tc.name, err)
+		}
+		if outcome != tc.expectedOutcome || len(platform.closed) != tc.expectedClosed || len(platform.deletedBranches) != tc.expectedClosed {
+			t.Errorf("For case %s, expected outcome %s and %d closed, but got %s and %v", tc.name, tc.expectedOutcome, tc.expectedClosed, outcome, platform.closed)
+		}
+		if !platform.openOnly {
+			t.Errorf("For case %s, expected the key lookup to be limited to open merge requests", tc.name)
+		}
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os/exec"
 
 	"github.com/cidverse/go-vcs"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
 	"github.com/cidverse/go-vcsapp/pkg/task/taskcommon"
 	"github.com/rs/zerolog/log"
 )
@@ -67,7 +68,7 @@ func (n CLITask) Execute(ctx taskcommon.TaskContext) error {
 	}
 
 	// create merge request
-	err = ctx.Platform.CreateMergeRequest(ctx.Repository, n.branchNameTemplate, n.commitMessageTemplate, "This is a test merge request.")
+	_, err = ctx.Platform.CreateMergeRequest(ctx.Repository, n.branchNameTemplate, n.commitMessageTemplate, "This is a test merge request.", api.MergeRequestOptions{})
 	if err != nil {
 		return fmt.Errorf("failed to create merge request: %w", err)
 	}
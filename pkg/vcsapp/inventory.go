@@ -0,0 +1,208 @@
+package vcsapp
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cidverse/go-ptr"
+	"github.com/cidverse/go-vcsapp/pkg/platform/api"
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+type InventoryFormat string
+
+const (
+	InventoryFormatJSON InventoryFormat = "json"
+	InventoryFormatCSV  InventoryFormat = "csv"
+	InventoryFormatYAML InventoryFormat = "yaml"
+)
+
+// ParseInventoryFormat returns the inventory format for the given name, or an error if the format is not supported
+func ParseInventoryFormat(name string) (InventoryFormat, error) {
+	format := InventoryFormat(name)
+	if !slices.Contains([]InventoryFormat{InventoryFormatJSON, InventoryFormatCSV, InventoryFormatYAML}, format) {
+		return "", fmt.Errorf("unsupported inventory format: %s", name)
+	}
+
+	return format, nil
+}
+
+type InventoryOpts struct {
+	BotUsernames []string // merge requests authored by these users are counted as bot merge requests, in addition to users of type bot
+}
+
+type InventoryEntry struct {
+	Platform             string         `json:"platform" yaml:"platform"`
+	Path                 string         `json:"path" yaml:"path"`
+	URL                  string         `json:"url" yaml:"url"`
+	DefaultBranch        string         `json:"defaultBranch" yaml:"defaultBranch"`
+	Visibility           string         `json:"visibility" yaml:"visibility"`
+	IsFork               bool           `json:"isFork" yaml:"isFork"`
+	IsEmpty              bool           `json:"isEmpty" yaml:"isEmpty"`
+	Languages            map[string]int `json:"languages" yaml:"languages"`
+	License              string         `json:"license" yaml:"license"`
+	Topics               []string       `json:"topics" yaml:"topics"`
+	CommitHash           string         `json:"commitHash" yaml:"commitHash"`
+	CommitDate           *time.Time     `json:"commitDate" yaml:"commitDate"`
+	OpenMergeRequests    int            `json:"openMergeRequests" yaml:"openMergeRequests"`
+	OpenBotMergeRequests int            `json:"openBotMergeRequests" yaml:"openBotMergeRequests"`
+	LastActivityAt       *time.Time     `json:"lastActivityAt" yaml:"lastActivityAt"`
+	Errors               []string       `json:"errors,omitempty" yaml:"errors,omitempty"` // Errors lists the data that could not be collected, the entry is incomplete if set
+}
+
+// Inventory collects an inventory of all repositories available on the platform
+func Inventory(platform api.Platform, opts InventoryOpts) ([]InventoryEntry, error) {
+	var result []InventoryEntry
+
+	// the languages are queried per repository below, IncludeLanguage would only add the primary language
+	repos, err := platform.Repositories(api.RepositoryListOpts{
+		IncludeCommitHash:        true,
+		IncludeMergeRequestCount: true,
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to list repositories: %w", err)
+	}
+
+	for _, repo := range repos {
+		entry := InventoryEntry{
+			Platform:          platform.Slug(),
+			Path:              repo.Path,
+			URL:               repo.WebURL,
+			DefaultBranch:     repo.DefaultBranch,
+			Visibility:        string(repo.Visibility),
+			IsFork:            repo.IsFork,
+			IsEmpty:           repo.IsEmpty,
+			License:           repo.LicenseName,
+			Topics:            repo.Topics,
+			CommitHash:        repo.CommitHash,
+			CommitDate:        repo.CommitDate,
+			OpenMergeRequests: repo.OpenMergeRequestCount,
+			LastActivityAt:    repo.LastActivityAt,
+		}
+
+		if !repo.IsEmpty {
+			languages, err := platform.Languages(repo)
+			if err != nil {
+				log.Warn().Err(err).Str("repository", repo.Path).Msg("failed to get languages")
+				entry.Errors = append(entry.Errors, fmt.Sprintf("failed to get languages: %v", err))
+			}
+			entry.Languages = languages
+		}
+
+		mrs, err := platform.MergeRequests(repo, api.MergeRequestSearchOptions{
+			State: ptr.Ptr(api.MergeRequestStateOpen),
+		})
+		if err != nil {
+			log.Warn().Err(err).Str("repository", repo.Path).Msg("failed to get merge requests")
+			entry.Errors = append(entry.Errors, fmt.Sprintf("failed to get merge requests: %v", err))
+		}
+		for _, mr := range mrs {
+			if mr.Author.Type == api.UserTypeBot || slices.Contains(opts.BotUsernames, mr.Author.Username) {
+				entry.OpenBotMergeRequests++
+			}
+		}
+
+		result = append(result, entry)
+	}
+
+	return result, nil
+}
+
+// ExportInventory collects the inventory of all repositories and writes it in the requested format
+func ExportInventory(platform api.Platform, w io.Writer, format InventoryFormat, opts InventoryOpts) error {
+	// validate the format before crawling all repositories
+	if _, err := ParseInventoryFormat(string(format)); err != nil {
+		return err
+	}
+
+	entries, err := Inventory(platform, opts)
+	if err != nil {
+		return err
+	}
+
+	return WriteInventory(w, entries, format)
+}
+
+// WriteInventory writes the inventory entries in the requested format
+func WriteInventory(w io.Writer, entries []InventoryEntry, format InventoryFormat) error {
+	switch format {
+	case InventoryFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(entries); err != nil {
+			return fmt.Errorf("failed to encode inventory as json: %w", err)
+		}
+	case InventoryFormatYAML:
+		encoder := yaml.NewEncoder(w)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(entries); err != nil {
+			return fmt.Errorf("failed to encode inventory as yaml: %w", err)
+		}
+		if err := encoder.Close(); err != nil {
+			return fmt.Errorf("failed to encode inventory as yaml: %w", err)
+		}
+	case InventoryFormatCSV:
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{"platform", "path", "url", "default_branch", "visibility", "is_fork", "is_empty", "languages", "license", "topics", "commit_hash", "commit_date", "open_merge_requests", "open_bot_merge_requests", "last_activity_at", "errors"})
+		for _, e := range entries {
+			_ = writer.Write([]string{
+				e.Platform,
+				e.Path,
+				e.URL,
+				e.DefaultBranch,
+				e.Visibility,
+				strconv.FormatBool(e.IsFork),
+				strconv.FormatBool(e.IsEmpty),
+				formatLanguages(e.Languages),
+				e.License,
+				strings.Join(e.Topics, ";"),
+				e.CommitHash,
+				formatTime(e.CommitDate),
+				strconv.Itoa(e.OpenMergeRequests),
+				strconv.Itoa(e.OpenBotMergeRequests),
+				formatTime(e.LastActivityAt),
+				strings.Join(e.Errors, ";"),
+			})
+		}
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("failed to write inventory as csv: %w", err)
+		}
+	default:
+		return fmt.Errorf("unsupported inventory format: %s", format)
+	}
+
+	return nil
+}
+
+// formatLanguages formats the languages as a sorted list of name=value pairs
+func formatLanguages(languages map[string]int) string {
+	var names []string
+	for name := range languages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		parts = append(parts, name+"="+strconv.Itoa(languages[name]))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,56 @@
+package vcsapp
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteInventoryCSV(t *testing.T) {
+	commitDate := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	entries := []InventoryEntry{
+		{
+			Platform:             "github",
+			Path:                 "cidverse/go-vcsapp",
+			DefaultBranch:        "main",
+			Languages:            map[string]int{"Shell": 10, "Go": 1000},
+			License:              "MIT License",
+			Topics:               []string{"go", "vcs"},
+			CommitHash:           "abc123",
+			CommitDate:           &commitDate,
+			OpenMergeRequests:    3,
+			OpenBotMergeRequests: 2,
+		},
+	}
+
+	var buf bytes.Buffer
+	err := WriteInventory(&buf, entries, InventoryFormatCSV)
+	assert.NoError(t, err)
+	expected := "platform,path,url,default_branch,visibility,is_fork,is_empty,languages,license,topics,commit_hash,commit_date,open_merge_requests,open_bot_merge_requests,last_activity_at,errors\n" +
+		"github,cidverse/go-vcsapp,,main,,false,false,Go=1000;Shell=10,MIT License,go;vcs,abc123,2024-01-02T03:04:05Z,3,2,,\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestWriteInventoryUnsupportedFormat(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteInventory(&buf, nil, "xml")
+	assert.Error(t, err)
+}
+
+func TestParseInventoryFormat(t *testing.T) {
+	format, err := ParseInventoryFormat("csv")
+	assert.NoError(t, err)
+	assert.Equal(t, InventoryFormatCSV, format)
+
+	_, err = ParseInventoryFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestExportInventoryUnsupportedFormat(t *testing.T) {
+	// the format is validated before the platform is queried
+	var buf bytes.Buffer
+	err := ExportInventory(nil, &buf, "xml", InventoryOpts{})
+	assert.ErrorContains(t, err, "unsupported inventory format")
+}
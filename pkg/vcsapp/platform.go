@@ -14,6 +14,10 @@ import (
 const (
 	AuthorName              = "VCSAPP_AUTHOR_NAME"
 	AuthorEMail             = "VCSAPP_AUTHOR_EMAIL"
+	SigningFormat           = "VCSAPP_SIGNING_FORMAT"
+	SigningKey              = "VCSAPP_SIGNING_KEY"
+	SigningKeyFile          = "VCSAPP_SIGNING_KEY_FILE"
+	SigningPassphrase       = "VCSAPP_SIGNING_PASSPHRASE"
 	GithubAppId             = "GITHUB_APP_ID"
 	GithubAppPrivateKey     = "GITHUB_APP_PRIVATE_KEY"
 	GithubAppPrivateKeyFile = "GITHUB_APP_PRIVATE_KEY_FILE"
@@ -21,6 +25,7 @@ const (
 	GithubToken             = "GITHUB_TOKEN"
 	GitlabServer            = "GITLAB_SERVER"
 	GitlabAccessToken       = "GITLAB_ACCESS_TOKEN"
+	GitlabCommitsAPI        = "GITLAB_COMMITS_API"
 )
 
 type PlatformConfig struct {
@@ -31,16 +36,31 @@ type PlatformConfig struct {
 	GitHubToken             string
 	GitLabServer            string
 	GitLabAccessToken       string
+	GitLabCommitsAPI        bool
 	Author                  api.GitAuthor
+	Signing                 api.SigningConfig // commit signing, only applies to platforms that create commits locally (GitHub signs API-created commits itself)
+	SigningKeyFile          string            // path to the signing key, used if Signing.Key is empty
 }
 
 func NewPlatform(platformConfig PlatformConfig) (api.Platform, error) {
 	// GitLab - as user
 	if platformConfig.GitLabServer != "" && platformConfig.GitLabAccessToken != "" {
+		signing := platformConfig.Signing
+		if signing.Key == "" && platformConfig.SigningKeyFile != "" {
+			// read signing key
+			signingKey, err := os.ReadFile(platformConfig.SigningKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read signing key file: %w", err)
+			}
+			signing.Key = string(signingKey)
+		}
+
 		platform, err := gitlabuser.NewPlatform(gitlabuser.Config{
 			Server:      platformConfig.GitLabServer,
 			AccessToken: platformConfig.GitLabAccessToken,
 			Author:      platformConfig.Author,
+			Signing:     signing,
+			CommitsAPI:  platformConfig.GitLabCommitsAPI,
 		})
 		return platform, err
 	}
@@ -107,7 +127,14 @@ func GetPlatformFromEnvironment() (api.Platform, error) {
 		GitHubToken:             env[GithubToken],
 		GitLabServer:            env[GitlabServer],
 		GitLabAccessToken:       env[GitlabAccessToken],
+		GitLabCommitsAPI:        env[GitlabCommitsAPI] == "true",
 		Author:                  author,
+		Signing: api.SigningConfig{
+			Format:     api.SigningFormat(env[SigningFormat]),
+			Key:        env[SigningKey],
+			Passphrase: env[SigningPassphrase],
+		},
+		SigningKeyFile: env[SigningKeyFile],
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize platform: %w. check the documentation and provide environment variables for at least one platform", err)